@@ -1,18 +1,30 @@
 package cmd
 
 import (
+	"errors"
 	"github.com/spf13/cobra"
 	"pagarme/transactions"
+	"time"
 )
 
 var boletoCmd = &cobra.Command{
 	Use:   "boleto",
 	Short: "Gerar boleto",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		output, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
 
 		tb := transactions.TransactionBuilder{}
 
-		amount, _ := cmd.Flags().GetFloat64("amount")
+		amount := moneyFlag(cmd, "amount")
 		tb.Amount(amount)
 
 		name, _ := cmd.Flags().GetString("name")
@@ -21,15 +33,97 @@ var boletoCmd = &cobra.Command{
 		document, _ := cmd.Flags().GetString("document")
 		tb.Document(document)
 
+		if postbackURL, _ := cmd.Flags().GetString("postbackUrl"); postbackURL != "" {
+			tb.PostbackURL(postbackURL)
+		}
+
+		if err := itemFlags(cmd, &tb); err != nil {
+			return err
+		}
+
+		if err := splitFlags(cmd, &tb); err != nil {
+			return err
+		}
+
+		expirationDate, _ := cmd.Flags().GetString("expirationDate")
+		expiresIn, _ := cmd.Flags().GetInt("expiresIn")
+		switch {
+		case expirationDate != "" && expiresIn > 0:
+			return errors.New("use either --expirationDate or --expiresIn")
+		case expirationDate != "":
+			date, err := time.ParseInLocation(dateLayout, expirationDate, time.Local)
+			if err != nil {
+				return &transactions.InvalidValueError{ValueParam: "expirationDate", Value: expirationDate}
+			}
+			tb.BoletoExpirationDate(date)
+		case expiresIn > 0:
+			tb.BoletoExpirationDate(transactions.AddBusinessDays(time.Now(), expiresIn))
+		}
+
+		if instructions, _ := cmd.Flags().GetString("instructions"); instructions != "" {
+			tb.BoletoInstructions(instructions)
+		}
+
+		if fine, ok := boletoChargeFlags(cmd, "fine"); ok {
+			tb.BoletoFine(fine)
+		}
+
+		if interest, ok := boletoChargeFlags(cmd, "interest"); ok {
+			tb.BoletoInterest(interest)
+		}
+
+		if rule, _ := cmd.Flags().GetString("rule"); rule != "" {
+			tb.BoletoRules(transactions.BoletoRule(rule))
+		}
+
+		if cmd.Flags().Changed("async") {
+			async, _ := cmd.Flags().GetBool("async")
+			tb.Async(async)
+		}
+
 		tb.PaymentMethod(transactions.BOLETO)
-		t := tb.Build()
-		transactions.NewClient().Execute(t, transactions.BODY)
+		t, err := tb.Build()
+		if err != nil {
+			return err
+		}
+		result, err := transactions.NewClient(options...).ExecuteContext(cmd.Context(), t, transactions.BODY)
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd.OutOrStdout(), output, *result)
 	},
 }
 
+// boletoChargeFlags reads the --<prefix>Days, --<prefix>Amount and
+// --<prefix>Percentage flags. It reports false when none was given.
+func boletoChargeFlags(cmd *cobra.Command, prefix string) (transactions.BoletoCharge, bool) {
+	charge := transactions.BoletoCharge{}
+	charge.Days, _ = cmd.Flags().GetInt(prefix + "Days")
+	charge.Amount = moneyFlag(cmd, prefix+"Amount")
+	charge.Percentage = *cmd.Flags().Lookup(prefix + "Percentage").Value.(*transactions.InterestRate)
+
+	return charge, charge != transactions.BoletoCharge{}
+}
+
 func init() {
 	rootCmd.AddCommand(boletoCmd)
-	boletoCmd.Flags().Float64P("amount", "a", 0.0, "Amount value")
+	boletoCmd.Flags().VarP(new(transactions.Money), "amount", "a", "Amount value")
 	boletoCmd.Flags().StringP("name", "n", "", "Name")
 	boletoCmd.Flags().StringP("document", "d", "", "Document")
+	boletoCmd.Flags().String("postbackUrl", "", "URL notified on status changes")
+	boletoCmd.Flags().StringArray("item", nil, "Item ID:TITLE:UNIT_PRICE:QUANTITY[:intangible] (repeatable)")
+	boletoCmd.Flags().String("splitFile", "", "YAML file with the split_rules between recipients")
+	boletoCmd.Flags().String("expirationDate", "", "Expiration date (YYYY-MM-DD)")
+	boletoCmd.Flags().Int("expiresIn", 0, "Expiration in business days from today")
+	boletoCmd.Flags().String("instructions", "", "Instructions printed on the boleto")
+	boletoCmd.Flags().Int("fineDays", 0, "Days after the expiration date to charge the fine")
+	boletoCmd.Flags().Var(new(transactions.Money), "fineAmount", "Fine amount")
+	boletoCmd.Flags().Var(new(transactions.InterestRate), "finePercentage", "Fine percentage (2.00)")
+	boletoCmd.Flags().Int("interestDays", 0, "Days after the expiration date to charge interest")
+	boletoCmd.Flags().Var(new(transactions.Money), "interestAmount", "Interest amount per day")
+	boletoCmd.Flags().Var(new(transactions.InterestRate), "interestPercentage", "Interest percentage per month (1.00)")
+	boletoCmd.Flags().String("rule", "", "Payment after expiration: strict_expiration_date or no_strict")
+	boletoCmd.Flags().Bool("async", false, "Answer before the boleto is processed")
+	boletoCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
 }
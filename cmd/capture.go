@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"pagarme/transactions"
+
+	"github.com/spf13/cobra"
+)
+
+var captureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Capturar transação cartão autorizada",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		output, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		id, _ := cmd.Flags().GetInt("id")
+		amount := moneyFlag(cmd, "amount")
+
+		result, err := transactions.NewClient(options...).CaptureContext(cmd.Context(), id, amount)
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd.OutOrStdout(), output, *result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(captureCmd)
+	captureCmd.Flags().IntP("id", "i", 0, "Transaction id")
+	captureCmd.Flags().VarP(new(transactions.Money), "amount", "a", "Amount value (default authorized amount)")
+	captureCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
+	captureCmd.MarkFlagRequired("id")
+}
@@ -1,7 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
 	"pagarme/transactions"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -9,11 +15,21 @@ import (
 var cartaoCmd = &cobra.Command{
 	Use:   "cartao",
 	Short: "Gerar cobramça cartão",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		output, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
 
 		tb := transactions.TransactionBuilder{}
 
-		amount, _ := cmd.Flags().GetFloat64("amount")
+		amount := moneyFlag(cmd, "amount")
 		tb.Amount(amount)
 
 		name, _ := cmd.Flags().GetString("name")
@@ -25,37 +41,187 @@ var cartaoCmd = &cobra.Command{
 		country, _ := cmd.Flags().GetString("country")
 		tb.Country(country)
 
-		cardNumber, _ := cmd.Flags().GetString("cardNumber")
-		tb.CardNumber(cardNumber)
+		if err := customerFlags(cmd, &tb); err != nil {
+			return err
+		}
 
-		cardHolderName, _ := cmd.Flags().GetString("cardHolderName")
-		tb.CardHolderName(cardHolderName)
+		if err := itemFlags(cmd, &tb); err != nil {
+			return err
+		}
 
-		cardExpirationDate, _ := cmd.Flags().GetString("cardExpirationDate")
-		tb.CardExpirationDate(cardExpirationDate)
+		if err := splitFlags(cmd, &tb); err != nil {
+			return err
+		}
 
 		cardCVV, _ := cmd.Flags().GetString("cardCVV")
-		tb.CardCVV(cardCVV)
 
-		tb.PaymentMethod(transactions.CREDIT_CARD)
+		if cardID, _ := cmd.Flags().GetString("cardId"); cardID != "" {
+			tb.CardID(cardID)
+			if cardCVV != "" {
+				tb.CardCVV(cardCVV)
+			}
+		} else {
+			cardNumber, _ := cmd.Flags().GetString("cardNumber")
+			tb.CardNumber(cardNumber)
+
+			cardHolderName, _ := cmd.Flags().GetString("cardHolderName")
+			tb.CardHolderName(cardHolderName)
+
+			cardExpirationDate, _ := cmd.Flags().GetString("cardExpirationDate")
+			tb.CardExpirationDate(cardExpirationDate)
+
+			tb.CardCVV(cardCVV)
+		}
+
+		installments, _ := cmd.Flags().GetInt("installments")
+		tb.Installments(installments)
+
+		capture, _ := cmd.Flags().GetBool("capture")
+		tb.Capture(capture)
+
+		if postbackURL, _ := cmd.Flags().GetString("postbackUrl"); postbackURL != "" {
+			tb.PostbackURL(postbackURL)
+		}
 
-		transaction := tb.Build()
-		client := transactions.NewClient()
-		publicKey := client.RecoverPublicKey()
-		transaction.CreateCardHash(publicKey)
+		tb.PaymentMethod(transactions.CREDIT_CARD)
 
-		client.Execute(transaction, transactions.BASIC_AUTH)
+		transaction, err := tb.Build()
+		if err != nil {
+			return err
+		}
+		client := transactions.NewClient(options...)
+		result, err := client.ExecuteWithCardHashContext(cmd.Context(), transaction, transactions.BASIC_AUTH)
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd.OutOrStdout(), output, *result)
 	},
 }
 
+// customerFile is the JSON file of --customerFile, in the format of the
+// Pagar.me API.
+type customerFile struct {
+	Email        string                 `json:"email"`
+	PhoneNumbers []string               `json:"phone_numbers"`
+	Birthday     *transactions.Date     `json:"birthday"`
+	Billing      *transactions.Billing  `json:"billing"`
+	Shipping     *transactions.Shipping `json:"shipping"`
+}
+
+// customerFlags reads --customerFile and then --email, --phone and
+// --birthday, which replace the values of the file.
+func customerFlags(cmd *cobra.Command, tb *transactions.TransactionBuilder) error {
+	customer := customerFile{}
+	if path, _ := cmd.Flags().GetString("customerFile"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &customer); err != nil {
+			return fmt.Errorf("invalid customer file %v: %w", path, err)
+		}
+	}
+
+	if email, _ := cmd.Flags().GetString("email"); email != "" {
+		customer.Email = email
+	}
+
+	if phones, _ := cmd.Flags().GetStringSlice("phone"); len(phones) > 0 {
+		customer.PhoneNumbers = phones
+	}
+
+	if birthday, _ := cmd.Flags().GetString("birthday"); birthday != "" {
+		date, err := time.Parse(dateLayout, birthday)
+		if err != nil {
+			return &transactions.InvalidValueError{ValueParam: "birthday", Value: birthday}
+		}
+		customer.Birthday = &transactions.Date{Time: date}
+	}
+
+	if customer.Email != "" {
+		tb.Email(customer.Email)
+	}
+	for _, phone := range customer.PhoneNumbers {
+		tb.PhoneNumber(phone)
+	}
+	if customer.Birthday != nil && !customer.Birthday.IsZero() {
+		tb.Birthday(*customer.Birthday)
+	}
+	if customer.Billing != nil {
+		tb.Billing(*customer.Billing)
+	}
+	if customer.Shipping != nil {
+		tb.Shipping(*customer.Shipping)
+	}
+	return nil
+}
+
+// itemFlags adds the items of the repeated --item flags.
+func itemFlags(cmd *cobra.Command, tb *transactions.TransactionBuilder) error {
+	values, _ := cmd.Flags().GetStringArray("item")
+	for _, value := range values {
+		item, err := parseItem(value)
+		if err != nil {
+			return err
+		}
+		tb.AddItem(item)
+	}
+	return nil
+}
+
+// parseItem reads an item as ID:TITLE:UNIT_PRICE:QUANTITY, optionally
+// followed by :intangible, such as "r123:Camiseta:29.90:2". The title may
+// contain colons.
+func parseItem(value string) (transactions.Item, error) {
+	invalid := &transactions.InvalidValueError{ValueParam: "item", Value: value}
+
+	fields := strings.Split(value, ":")
+	item := transactions.Item{Tangible: true}
+	if last := fields[len(fields)-1]; last == "intangible" || last == "tangible" {
+		item.Tangible = last == "tangible"
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) < 4 {
+		return item, invalid
+	}
+
+	n := len(fields)
+	price, err := transactions.ParseMoney(fields[n-2])
+	if err != nil {
+		return item, invalid
+	}
+	quantity, err := strconv.Atoi(fields[n-1])
+	if err != nil {
+		return item, invalid
+	}
+
+	item.ID = fields[0]
+	item.Title = strings.Join(fields[1:n-2], ":")
+	item.UnitPrice = price
+	item.Quantity = quantity
+	return item, nil
+}
+
 func init() {
 	rootCmd.AddCommand(cartaoCmd)
-	cartaoCmd.Flags().Float64P("amount", "a", 0.0, "Amount value")
+	cartaoCmd.Flags().VarP(new(transactions.Money), "amount", "a", "Amount value")
 	cartaoCmd.Flags().StringP("name", "n", "", "Name")
 	cartaoCmd.Flags().StringP("document", "d", "", "Document")
 	cartaoCmd.Flags().StringP("country", "C", "", "Country")
+	cartaoCmd.Flags().String("email", "", "Customer email")
+	cartaoCmd.Flags().StringSlice("phone", nil, "Customer phone number in E.164 format, such as +5511999998888 (repeatable)")
+	cartaoCmd.Flags().String("birthday", "", "Customer birthday (YYYY-MM-DD)")
+	cartaoCmd.Flags().String("customerFile", "", "JSON file with email, phone_numbers, birthday, billing and shipping")
+	cartaoCmd.Flags().StringArray("item", nil, "Item ID:TITLE:UNIT_PRICE:QUANTITY[:intangible] (repeatable)")
+	cartaoCmd.Flags().String("splitFile", "", "YAML file with the split_rules between recipients")
 	cartaoCmd.Flags().StringP("cardNumber", "c", "", "Card Number")
 	cartaoCmd.Flags().StringP("cardHolderName", "N", "", "Card Holder Name")
 	cartaoCmd.Flags().StringP("cardExpirationDate", "e", "", "Card Expiration Date")
 	cartaoCmd.Flags().StringP("cardCVV", "v", "", "Card CVV")
+	cartaoCmd.Flags().String("cardId", "", "Id of a card stored with cliente add-card, instead of the card flags (the CVV is optional)")
+	cartaoCmd.Flags().IntP("installments", "i", 1, "Number of installments (the amount must include the interest)")
+	cartaoCmd.Flags().Bool("capture", true, "Capture the transaction (false only authorizes)")
+	cartaoCmd.Flags().String("postbackUrl", "", "URL notified on status changes")
+	cartaoCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"pagarme/transactions"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var clienteCmd = &cobra.Command{
+	Use:   "cliente",
+	Short: "Gerenciar clientes e cartões salvos",
+}
+
+var clienteCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Criar cliente",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		customer := transactions.Customer{}
+		customer.ExternalID, _ = cmd.Flags().GetString("externalId")
+		customer.Name, _ = cmd.Flags().GetString("name")
+		customer.Email, _ = cmd.Flags().GetString("email")
+		customer.Country, _ = cmd.Flags().GetString("country")
+		customer.PhoneNumbers, _ = cmd.Flags().GetStringSlice("phone")
+		customer.Document, _ = cmd.Flags().GetString("document")
+
+		if birthday, _ := cmd.Flags().GetString("birthday"); birthday != "" {
+			date, err := time.Parse(dateLayout, birthday)
+			if err != nil {
+				return &transactions.InvalidValueError{ValueParam: "birthday", Value: birthday}
+			}
+			customer.Birthday = &transactions.Date{Time: date}
+		}
+
+		result, err := transactions.NewClient(options...).CreateCustomerContext(cmd.Context(), customer)
+		if err != nil {
+			return err
+		}
+
+		return printCustomers(cmd, *result)
+	},
+}
+
+var clienteGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Consultar cliente",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		id, err := customerID(args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := transactions.NewClient(options...).GetCustomerContext(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		return printCustomers(cmd, *result)
+	},
+}
+
+var clienteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar clientes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		it := transactions.NewClient(options...).ListCustomersContext(cmd.Context())
+
+		var result []transactions.CustomerResponse
+		for (limit <= 0 || len(result) < limit) && it.Next() {
+			result = append(result, it.Customer())
+		}
+
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		return printCustomers(cmd, result...)
+	},
+}
+
+var clienteAddCardCmd = &cobra.Command{
+	Use:   "add-card <id>",
+	Short: "Salvar cartão do cliente",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		id, err := customerID(args[0])
+		if err != nil {
+			return err
+		}
+
+		card := transactions.CardData{}
+		card.Number, _ = cmd.Flags().GetString("cardNumber")
+		card.HolderName, _ = cmd.Flags().GetString("cardHolderName")
+		card.ExpirationDate, _ = cmd.Flags().GetString("cardExpirationDate")
+		card.CVV, _ = cmd.Flags().GetString("cardCVV")
+
+		result, err := transactions.NewClient(options...).CreateCardWithCardHashContext(cmd.Context(), id, card)
+		if err != nil {
+			return err
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		return printCards(cmd.OutOrStdout(), output, *result)
+	},
+}
+
+var clienteCardsCmd = &cobra.Command{
+	Use:   "cards <id>",
+	Short: "Listar cartões salvos do cliente",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		id, err := customerID(args[0])
+		if err != nil {
+			return err
+		}
+
+		it := transactions.NewClient(options...).ListCardsContext(cmd.Context(), id)
+
+		var result []transactions.Card
+		for it.Next() {
+			result = append(result, it.Card())
+		}
+
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		return printCards(cmd.OutOrStdout(), output, result...)
+	},
+}
+
+func customerID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &transactions.InvalidValueError{ValueParam: "id", Value: value}
+	}
+	return id, nil
+}
+
+// printCustomers writes the customers as an indented JSON array or as a
+// table with the main fields, as printTransactions.
+func printCustomers(cmd *cobra.Command, values ...transactions.CustomerResponse) error {
+	w := cmd.OutOrStdout()
+	output, _ := cmd.Flags().GetString("output")
+
+	switch output {
+	case "json":
+		return printJSON(w, values, []transactions.CustomerResponse{})
+	case "table":
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tEXTERNO\tNOME\tEMAIL\tDOCUMENTO\tCRIADO EM")
+		for _, row := range values {
+			document := ""
+			if len(row.Documents) > 0 {
+				document = row.Documents[0].Number
+			}
+			fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\n", row.ID, row.ExternalID, row.Name, row.Email, document, row.DateCreated.Format("2006-01-02 15:04"))
+		}
+		return table.Flush()
+	}
+
+	return &transactions.InvalidValueError{ValueParam: "output", Value: output}
+}
+
+func printCards(w io.Writer, output string, values ...transactions.Card) error {
+	switch output {
+	case "json":
+		return printJSON(w, values, []transactions.Card{})
+	case "table":
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tBANDEIRA\tCARTAO\tTITULAR\tVALIDADE")
+		for _, row := range values {
+			fmt.Fprintf(table, "%v\t%v\t%v...%v\t%v\t%v\n", row.ID, row.Brand, row.FirstDigits, row.LastDigits, row.HolderName, row.ExpirationDate)
+		}
+		return table.Flush()
+	}
+
+	return &transactions.InvalidValueError{ValueParam: "output", Value: output}
+}
+
+func init() {
+	rootCmd.AddCommand(clienteCmd)
+	clienteCmd.AddCommand(clienteCreateCmd, clienteGetCmd, clienteListCmd, clienteAddCardCmd, clienteCardsCmd)
+	clienteCmd.PersistentFlags().StringP("output", "o", "table", "Output format: table or json")
+
+	clienteCreateCmd.Flags().String("externalId", "", "Customer id in your system")
+	clienteCreateCmd.Flags().StringP("name", "n", "", "Name")
+	clienteCreateCmd.Flags().StringP("document", "d", "", "CPF or CNPJ")
+	clienteCreateCmd.Flags().StringP("country", "C", "br", "Country")
+	clienteCreateCmd.Flags().String("email", "", "Email")
+	clienteCreateCmd.Flags().StringSlice("phone", nil, "Phone number in E.164 format, such as +5511999998888 (repeatable)")
+	clienteCreateCmd.Flags().String("birthday", "", "Birthday (YYYY-MM-DD)")
+
+	clienteListCmd.Flags().IntP("limit", "l", 0, "Maximum number of customers (default all)")
+
+	clienteAddCardCmd.Flags().StringP("cardNumber", "c", "", "Card Number")
+	clienteAddCardCmd.Flags().StringP("cardHolderName", "N", "", "Card Holder Name")
+	clienteAddCardCmd.Flags().StringP("cardExpirationDate", "e", "", "Card Expiration Date")
+	clienteAddCardCmd.Flags().StringP("cardCVV", "v", "", "Card CVV")
+}
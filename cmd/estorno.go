@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"pagarme/transactions"
+
+	"github.com/spf13/cobra"
+)
+
+var estornoCmd = &cobra.Command{
+	Use:   "estorno",
+	Short: "Estornar transação",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		output, err := outputFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		client := transactions.NewClient(options...)
+
+		id, _ := cmd.Flags().GetInt("id")
+		amount := moneyFlag(cmd, "amount")
+
+		bankCode, _ := cmd.Flags().GetString("bankCode")
+		if bankCode == "" {
+			result, err := client.RefundContext(cmd.Context(), id, amount)
+			if err != nil {
+				return err
+			}
+			return printResult(cmd.OutOrStdout(), output, *result)
+		}
+
+		account := transactions.BankAccount{BankCode: bankCode}
+		account.Agencia, _ = cmd.Flags().GetString("agencia")
+		account.AgenciaDv, _ = cmd.Flags().GetString("agenciaDv")
+		account.Conta, _ = cmd.Flags().GetString("conta")
+		account.ContaDv, _ = cmd.Flags().GetString("contaDv")
+		account.Type, _ = cmd.Flags().GetString("accountType")
+		account.DocumentNumber, _ = cmd.Flags().GetString("document")
+		account.LegalName, _ = cmd.Flags().GetString("legalName")
+
+		result, err := client.RefundBoletoContext(cmd.Context(), id, amount, account)
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd.OutOrStdout(), output, *result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(estornoCmd)
+	estornoCmd.Flags().IntP("id", "i", 0, "Transaction id")
+	estornoCmd.Flags().VarP(new(transactions.Money), "amount", "a", "Amount value (default paid amount)")
+	estornoCmd.Flags().StringP("bankCode", "b", "", "Bank code (boleto refund)")
+	estornoCmd.Flags().String("agencia", "", "Agency (boleto refund)")
+	estornoCmd.Flags().String("agenciaDv", "", "Agency check digit (boleto refund)")
+	estornoCmd.Flags().String("conta", "", "Account (boleto refund)")
+	estornoCmd.Flags().String("contaDv", "", "Account check digit (boleto refund)")
+	estornoCmd.Flags().String("accountType", "conta_corrente", "Account type (boleto refund)")
+	estornoCmd.Flags().StringP("document", "d", "", "Account holder document (boleto refund)")
+	estornoCmd.Flags().StringP("legalName", "n", "", "Account holder name (boleto refund)")
+	estornoCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
+	estornoCmd.MarkFlagRequired("id")
+}
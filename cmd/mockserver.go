@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"pagarme/pagarmetest"
+
+	"github.com/spf13/cobra"
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Iniciar emulador local da API Pagar.me",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		addr, _ := cmd.Flags().GetString("addr")
+		apiKey, _ := cmd.Flags().GetString("apiKey")
+		encryptionKey, _ := cmd.Flags().GetString("encryptionKey")
+
+		server := pagarmetest.New(apiKey)
+		if encryptionKey != "" {
+			server.SetEncryptionKey(encryptionKey)
+		}
+
+		httpServer := &http.Server{Addr: addr, Handler: server}
+		go func() {
+			<-cmd.Context().Done()
+			httpServer.Close()
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Listening on http://%v\n", addr)
+
+		err := httpServer.ListenAndServe()
+		if err == http.ErrServerClosed {
+			server.Close()
+			return nil
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+	mockServerCmd.Flags().String("addr", "localhost:8080", "Address to listen on")
+	mockServerCmd.Flags().String("apiKey", "ak_test_key", "Api key accepted by the server")
+	mockServerCmd.Flags().String("encryptionKey", "", "Encryption key accepted by the server")
+}
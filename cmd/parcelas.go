@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"pagarme/transactions"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var parcelasCmd = &cobra.Command{
+	Use:   "parcelas",
+	Short: "Calcular parcelas e juros",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		installmentOptions := transactions.InstallmentOptions{Amount: moneyFlag(cmd, "amount")}
+		installmentOptions.MaxInstallments, _ = cmd.Flags().GetInt("maxInstallments")
+		installmentOptions.FreeInstallments, _ = cmd.Flags().GetInt("freeInstallments")
+		installmentOptions.InterestRate = *cmd.Flags().Lookup("interestRate").Value.(*transactions.InterestRate)
+
+		if offline, _ := cmd.Flags().GetBool("offline"); offline {
+			installments, err := transactions.CalculateInstallments(installmentOptions)
+			if err != nil {
+				return err
+			}
+			return printInstallments(cmd.OutOrStdout(), installments)
+		}
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		installments, err := transactions.NewClient(options...).CalculateInstallmentsContext(cmd.Context(), installmentOptions)
+		if err != nil {
+			return err
+		}
+		return printInstallments(cmd.OutOrStdout(), installments)
+	},
+}
+
+func printInstallments(w io.Writer, installments []transactions.Installment) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PARCELAS\tVALOR DA PARCELA\tTOTAL")
+	for _, row := range installments {
+		fmt.Fprintf(table, "%vx\t%v\t%v\n", row.Installment, row.InstallmentAmount.Decimal(), row.Amount.Decimal())
+	}
+	return table.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(parcelasCmd)
+	parcelasCmd.Flags().VarP(new(transactions.Money), "amount", "a", "Amount value")
+	parcelasCmd.Flags().IntP("maxInstallments", "m", transactions.MAX_INSTALLMENTS, "Maximum number of installments")
+	parcelasCmd.Flags().IntP("freeInstallments", "f", 1, "Installments without interest")
+	parcelasCmd.Flags().VarP(new(transactions.InterestRate), "interestRate", "i", "Interest rate per installment, in percent (1.99)")
+	parcelasCmd.Flags().Bool("offline", false, "Calculate locally instead of requesting Pagar.me")
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pagarme/transactions"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var recebedorCmd = &cobra.Command{
+	Use:   "recebedor",
+	Short: "Gerenciar recebedores e contas bancárias",
+}
+
+var recebedorCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Criar recebedor",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		recipient := transactions.Recipient{}
+		recipient.BankAccount = bankAccountFlags(cmd)
+		recipient.BankAccountID, _ = cmd.Flags().GetInt("bankAccountId")
+		recipient.TransferSettings = transferFlags(cmd)
+		recipient.AnticipationSettings = anticipationFlags(cmd)
+		recipient.PostbackURL, _ = cmd.Flags().GetString("postbackUrl")
+
+		result, err := transactions.NewClient(options...).CreateRecipientContext(cmd.Context(), recipient)
+		if err != nil {
+			return err
+		}
+
+		return printRecipients(cmd, *result)
+	},
+}
+
+var recebedorGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Consultar recebedor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		result, err := transactions.NewClient(options...).GetRecipientContext(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		return printRecipients(cmd, *result)
+	},
+}
+
+var recebedorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar recebedores",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		it := transactions.NewClient(options...).ListRecipientsContext(cmd.Context())
+
+		var result []transactions.RecipientResponse
+		for (limit <= 0 || len(result) < limit) && it.Next() {
+			result = append(result, it.Recipient())
+		}
+
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		return printRecipients(cmd, result...)
+	},
+}
+
+var recebedorTransferCmd = &cobra.Command{
+	Use:   "transfer <id>",
+	Short: "Alterar transferências do recebedor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		result, err := transactions.NewClient(options...).UpdateTransferSettingsContext(cmd.Context(), args[0], transferFlags(cmd))
+		if err != nil {
+			return err
+		}
+
+		return printRecipients(cmd, *result)
+	},
+}
+
+var recebedorAnticipationCmd = &cobra.Command{
+	Use:   "anticipation <id>",
+	Short: "Alterar antecipação automática do recebedor",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		result, err := transactions.NewClient(options...).UpdateAnticipationSettingsContext(cmd.Context(), args[0], anticipationFlags(cmd))
+		if err != nil {
+			return err
+		}
+
+		return printRecipients(cmd, *result)
+	},
+}
+
+var recebedorBankAccountCmd = &cobra.Command{
+	Use:   "bank-account <id>",
+	Short: "Alterar conta bancária do recebedor (mesmo documento)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		accountID, _ := cmd.Flags().GetInt("bankAccountId")
+		client := transactions.NewClient(options...)
+		result, err := client.UpdateRecipientBankAccountContext(cmd.Context(), args[0], bankAccountFlags(cmd), accountID)
+		if err != nil {
+			return err
+		}
+
+		return printRecipients(cmd, *result)
+	},
+}
+
+var recebedorBankAccountsCmd = &cobra.Command{
+	Use:   "bank-accounts",
+	Short: "Listar contas bancárias",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		document, _ := cmd.Flags().GetString("document")
+		limit, _ := cmd.Flags().GetInt("limit")
+		it := transactions.NewClient(options...).ListBankAccountsContext(cmd.Context(), document)
+
+		var result []transactions.BankAccount
+		for (limit <= 0 || len(result) < limit) && it.Next() {
+			result = append(result, it.BankAccount())
+		}
+
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		return printBankAccounts(cmd.OutOrStdout(), output, result...)
+	},
+}
+
+// bankAccountFlags returns the account of the bank account flags, or nil
+// when --bankCode is not given.
+func bankAccountFlags(cmd *cobra.Command) *transactions.BankAccount {
+	bankCode, _ := cmd.Flags().GetString("bankCode")
+	if bankCode == "" {
+		return nil
+	}
+
+	account := &transactions.BankAccount{BankCode: bankCode}
+	account.Agencia, _ = cmd.Flags().GetString("agencia")
+	account.AgenciaDv, _ = cmd.Flags().GetString("agenciaDv")
+	account.Conta, _ = cmd.Flags().GetString("conta")
+	account.ContaDv, _ = cmd.Flags().GetString("contaDv")
+	account.Type, _ = cmd.Flags().GetString("accountType")
+	account.DocumentNumber, _ = cmd.Flags().GetString("document")
+	account.LegalName, _ = cmd.Flags().GetString("legalName")
+	return account
+}
+
+// transferFlags reads the transfer flags. Without --transferDay the day
+// follows the interval: none for daily, Friday for weekly and the first day
+// of the month for monthly.
+func transferFlags(cmd *cobra.Command) transactions.TransferSettings {
+	settings := transactions.TransferSettings{}
+	settings.TransferEnabled, _ = cmd.Flags().GetBool("transferEnabled")
+	interval, _ := cmd.Flags().GetString("transferInterval")
+	settings.TransferInterval = transactions.TransferInterval(interval)
+
+	switch {
+	case cmd.Flags().Changed("transferDay"):
+		settings.TransferDay, _ = cmd.Flags().GetInt("transferDay")
+	case settings.TransferInterval == transactions.WEEKLY:
+		settings.TransferDay = 5
+	case settings.TransferInterval == transactions.MONTHLY:
+		settings.TransferDay = 1
+	}
+	return settings
+}
+
+func anticipationFlags(cmd *cobra.Command) transactions.AnticipationSettings {
+	settings := transactions.AnticipationSettings{}
+	settings.AutomaticAnticipationEnabled, _ = cmd.Flags().GetBool("anticipationEnabled")
+	anticipationType, _ := cmd.Flags().GetString("anticipationType")
+	settings.AutomaticAnticipationType = transactions.AnticipationType(anticipationType)
+	settings.AutomaticAnticipationDays, _ = cmd.Flags().GetIntSlice("anticipationDays")
+	settings.AnticipatableVolumePercentage, _ = cmd.Flags().GetInt("anticipationPercentage")
+	return settings
+}
+
+func addBankAccountFlags(flags *pflag.FlagSet) {
+	flags.StringP("bankCode", "b", "", "Bank code, such as 001 or 341")
+	flags.String("agencia", "", "Agency")
+	flags.String("agenciaDv", "", "Agency check digit")
+	flags.String("conta", "", "Account")
+	flags.String("contaDv", "", "Account check digit")
+	flags.String("accountType", "conta_corrente", "Account type (conta_corrente, conta_poupanca, conta_corrente_conjunta or conta_poupanca_conjunta)")
+	flags.StringP("document", "d", "", "Account holder document")
+	flags.StringP("legalName", "n", "", "Account holder name (up to 30 characters)")
+	flags.Int("bankAccountId", 0, "Existing bank account id, instead of the account flags")
+}
+
+func addTransferFlags(flags *pflag.FlagSet) {
+	flags.Bool("transferEnabled", true, "Transfer the balance automatically")
+	flags.String("transferInterval", "weekly", "Transfer interval: daily, weekly or monthly")
+	flags.Int("transferDay", 0, "Transfer weekday (1-5) or day of the month (1-31), 0 for daily (default 5 for weekly, 1 for monthly)")
+}
+
+func addAnticipationFlags(flags *pflag.FlagSet) {
+	flags.Bool("anticipationEnabled", false, "Anticipate receivables automatically")
+	flags.String("anticipationType", "", "Anticipation type: full or 1025")
+	flags.IntSlice("anticipationDays", nil, "Days of the month to anticipate, for 1025")
+	flags.Int("anticipationPercentage", 0, "Percentage of the receivables that can be anticipated")
+}
+
+// printRecipients writes the recipients as an indented JSON array or as a
+// table with the main fields, as printTransactions.
+func printRecipients(cmd *cobra.Command, values ...transactions.RecipientResponse) error {
+	w := cmd.OutOrStdout()
+	output, _ := cmd.Flags().GetString("output")
+
+	switch output {
+	case "json":
+		return printJSON(w, values, []transactions.RecipientResponse{})
+	case "table":
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tSTATUS\tTRANSFERENCIA\tANTECIPACAO\tCONTA\tCRIADO EM")
+		for _, row := range values {
+			transfer := "-"
+			if row.TransferEnabled {
+				transfer = fmt.Sprintf("%v %v", row.TransferInterval, row.TransferDay)
+			}
+			anticipation := "-"
+			if row.AutomaticAnticipationEnabled {
+				anticipation = fmt.Sprintf("%v %v%%", row.AutomaticAnticipationType, row.AnticipatableVolumePercentage)
+			}
+			fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\n", row.ID, row.Status, transfer, anticipation, formatBankAccount(row.BankAccount), row.DateCreated.Format("2006-01-02 15:04"))
+		}
+		return table.Flush()
+	}
+
+	return &transactions.InvalidValueError{ValueParam: "output", Value: output}
+}
+
+func printBankAccounts(w io.Writer, output string, values ...transactions.BankAccount) error {
+	switch output {
+	case "json":
+		return printJSON(w, values, []transactions.BankAccount{})
+	case "table":
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tCONTA\tTIPO\tDOCUMENTO\tTITULAR")
+		for _, row := range values {
+			fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\n", row.ID, formatBankAccount(row), row.Type, row.DocumentNumber, row.LegalName)
+		}
+		return table.Flush()
+	}
+
+	return &transactions.InvalidValueError{ValueParam: "output", Value: output}
+}
+
+// printJSON writes values as an indented JSON array, empty when values is
+// nil.
+func printJSON(w io.Writer, values interface{}, empty interface{}) error {
+	if data, _ := json.Marshal(values); string(data) == "null" {
+		values = empty
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// formatBankAccount writes an account as bank agency-dv account-dv.
+func formatBankAccount(account transactions.BankAccount) string {
+	agencia := account.Agencia
+	if account.AgenciaDv != "" {
+		agencia += "-" + account.AgenciaDv
+	}
+	return fmt.Sprintf("%v %v %v-%v", account.BankCode, agencia, account.Conta, account.ContaDv)
+}
+
+func init() {
+	rootCmd.AddCommand(recebedorCmd)
+	recebedorCmd.AddCommand(recebedorCreateCmd, recebedorGetCmd, recebedorListCmd, recebedorTransferCmd,
+		recebedorAnticipationCmd, recebedorBankAccountCmd, recebedorBankAccountsCmd)
+	recebedorCmd.PersistentFlags().StringP("output", "o", "table", "Output format: table or json")
+
+	addBankAccountFlags(recebedorCreateCmd.Flags())
+	addTransferFlags(recebedorCreateCmd.Flags())
+	addAnticipationFlags(recebedorCreateCmd.Flags())
+	recebedorCreateCmd.Flags().String("postbackUrl", "", "URL notified on status changes")
+
+	recebedorListCmd.Flags().IntP("limit", "l", 0, "Maximum number of recipients (default all)")
+	addTransferFlags(recebedorTransferCmd.Flags())
+	addAnticipationFlags(recebedorAnticipationCmd.Flags())
+	addBankAccountFlags(recebedorBankAccountCmd.Flags())
+
+	recebedorBankAccountsCmd.Flags().StringP("document", "d", "", "Account holder document")
+	recebedorBankAccountsCmd.Flags().IntP("limit", "l", 0, "Maximum number of bank accounts (default all)")
+}
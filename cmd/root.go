@@ -1,31 +1,64 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"pagarme/transactions"
+	"strings"
+	"syscall"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var live bool
+var verbose bool
 
 var rootCmd = &cobra.Command{
-	Use:   "pagarme",
-	Short: "Stone test",
+	Use:           "pagarme",
+	Short:         "Stone test",
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	// Cancelled on Ctrl+C or SIGTERM so in flight charges are abandoned.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		printError(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// printError writes each problem of a transactions.BuildError on its own
+// line, so every invalid flag is reported at once.
+func printError(w io.Writer, err error) {
+	buildError, ok := err.(*transactions.BuildError)
+	if !ok {
+		fmt.Fprintln(w, err)
+		return
+	}
+
+	fmt.Fprintln(w, "Invalid transaction:")
+	for _, err := range buildError.Errors {
+		fmt.Fprintln(w, "  -", err)
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pagarme.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&live, "live", false, "Allow the use of a live (ak_live_) api key")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log the requests and responses, with card data and keys redacted")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
@@ -47,10 +80,50 @@ func initConfig() {
 		viper.SetConfigName(".pagarme")
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	viper.SetEnvPrefix("pagarme")
+	viper.AutomaticEnv() // read in environment variables that match (PAGARME_API_KEY, PAGARME_ENCRYPTION_KEY, PAGARME_BASE_URL)
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. The notice goes to stderr so it
+	// doesn't mix with the --output json of the commands.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// moneyFlag returns the value of a flag registered with
+// VarP(new(transactions.Money), ...). Amounts are parsed by
+// transactions.ParseMoney, so both 1234.56 and 1.234,56 are accepted.
+func moneyFlag(cmd *cobra.Command, name string) transactions.Money {
+	return *cmd.Flags().Lookup(name).Value.(*transactions.Money)
+}
+
+// clientOptions returns the keys read by initConfig as client options. A live
+// api key is refused unless the --live flag was given. With --verbose the
+// client logs go to stderr.
+func clientOptions() ([]transactions.Option, error) {
+	apiKey := viper.GetString("api_key")
+	if apiKey == "" {
+		return nil, errors.New("api_key not configured. Set it in the config file or in PAGARME_API_KEY")
 	}
+
+	if strings.HasPrefix(apiKey, "ak_live_") && !live {
+		return nil, errors.New("refusing to use a live api_key without the --live flag")
+	}
+
+	options := []transactions.Option{transactions.WithAPIKey(apiKey)}
+
+	if encryptionKey := viper.GetString("encryption_key"); encryptionKey != "" {
+		options = append(options, transactions.WithEncryptionKey(encryptionKey))
+	}
+
+	if baseURL := viper.GetString("base_url"); baseURL != "" {
+		options = append(options, transactions.WithBaseURL(baseURL))
+	}
+
+	if verbose {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		options = append(options, transactions.WithLogger(logger))
+	}
+
+	return options, nil
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"pagarme/transactions"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// splitFile is the YAML file of --splitFile, such as:
+//
+//	split_rules:
+//	  - recipient_id: re_ck8z3jg1f00h0oy6dhhrnsl6r
+//	    percentage: 85
+//	    liable: true
+//	    charge_processing_fee: true
+//	    charge_remainder_fee: true
+//	  - recipient_id: re_ck8z3jg1f00h0oy6dhhrnsl6s
+//	    percentage: 15
+//
+// Amounts are given in reais, such as amount: "10.50".
+type splitFile struct {
+	SplitRules []struct {
+		RecipientID         string `mapstructure:"recipient_id"`
+		Amount              string `mapstructure:"amount"`
+		Percentage          int    `mapstructure:"percentage"`
+		Liable              bool   `mapstructure:"liable"`
+		ChargeProcessingFee bool   `mapstructure:"charge_processing_fee"`
+		ChargeRemainderFee  bool   `mapstructure:"charge_remainder_fee"`
+	} `mapstructure:"split_rules"`
+}
+
+// splitFlags reads the split rules of --splitFile.
+func splitFlags(cmd *cobra.Command, tb *transactions.TransactionBuilder) error {
+	path, _ := cmd.Flags().GetString("splitFile")
+	if path == "" {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("invalid split file %v: %w", path, err)
+	}
+
+	file := splitFile{}
+	if err := v.Unmarshal(&file); err != nil {
+		return fmt.Errorf("invalid split file %v: %w", path, err)
+	}
+
+	rules := make([]transactions.SplitRule, len(file.SplitRules))
+	for i, rule := range file.SplitRules {
+		var amount transactions.Money
+		if rule.Amount != "" {
+			var err error
+			if amount, err = transactions.ParseMoney(rule.Amount); err != nil {
+				return &transactions.InvalidValueError{ValueParam: "split_rules.amount", Value: rule.Amount}
+			}
+		}
+
+		rules[i] = transactions.SplitRule{
+			RecipientID:         rule.RecipientID,
+			Amount:              amount,
+			Percentage:          rule.Percentage,
+			Liable:              rule.Liable,
+			ChargeProcessingFee: rule.ChargeProcessingFee,
+			ChargeRemainderFee:  rule.ChargeRemainderFee,
+		}
+	}
+
+	tb.SplitRules(rules...)
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"pagarme/transactions"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status <id>",
+	Short: "Consultar status da transação",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return &transactions.InvalidValueError{ValueParam: "id", Value: args[0]}
+		}
+
+		client := transactions.NewClient(options...)
+
+		wait, _ := cmd.Flags().GetBool("wait")
+		if !wait {
+			transaction, err := client.GetTransactionContext(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			cmd.Println(transaction.ID, transaction.Status)
+			return nil
+		}
+
+		var targets []transactions.Status
+		values, _ := cmd.Flags().GetStringSlice("target")
+		for _, value := range values {
+			target := transactions.Status(value)
+			if !target.Valid() {
+				return &transactions.InvalidValueError{ValueParam: "target", Value: value}
+			}
+			targets = append(targets, target)
+		}
+
+		ctx := cmd.Context()
+		if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		transaction, err := client.WaitForStatus(ctx, id, targets...)
+		if transaction != nil {
+			cmd.Println(transaction.ID, transaction.Status)
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolP("wait", "w", false, "Wait until the transaction reaches one of the target statuses")
+	statusCmd.Flags().StringSliceP("target", "T", []string{transactions.PAID.String()}, "Target statuses for --wait")
+	statusCmd.Flags().Duration("timeout", 0, "Maximum time to wait (default no limit)")
+}
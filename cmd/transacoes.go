@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"pagarme/documents"
+	"pagarme/transactions"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const dateLayout = "2006-01-02"
+
+var transacoesCmd = &cobra.Command{
+	Use:   "transacoes",
+	Short: "Consultar transações",
+}
+
+var transacoesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar transações",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		filter := transactions.TransactionFilter{}
+		status, _ := cmd.Flags().GetString("status")
+		filter.Status = transactions.Status(status)
+		filter.PaymentMethod, _ = cmd.Flags().GetString("paymentMethod")
+		document, _ := cmd.Flags().GetString("document")
+		filter.DocumentNumber = documents.Normalize(document)
+
+		if from, _ := cmd.Flags().GetString("from"); from != "" {
+			if filter.DateCreatedFrom, err = time.Parse(dateLayout, from); err != nil {
+				return &transactions.InvalidValueError{ValueParam: "from", Value: from}
+			}
+		}
+
+		if to, _ := cmd.Flags().GetString("to"); to != "" {
+			if filter.DateCreatedTo, err = time.Parse(dateLayout, to); err != nil {
+				return &transactions.InvalidValueError{ValueParam: "to", Value: to}
+			}
+			filter.DateCreatedTo = filter.DateCreatedTo.Add(24*time.Hour - time.Millisecond)
+		}
+
+		metadata, _ := cmd.Flags().GetStringSlice("metadata")
+		for _, pair := range metadata {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return &transactions.InvalidValueError{ValueParam: "metadata", Value: pair}
+			}
+			if filter.Metadata == nil {
+				filter.Metadata = map[string]string{}
+			}
+			filter.Metadata[kv[0]] = kv[1]
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		output, _ := cmd.Flags().GetString("output")
+
+		it := transactions.NewClient(options...).ListTransactionsContext(cmd.Context(), filter)
+
+		var result []transactions.TransactionResponse
+		for (limit <= 0 || len(result) < limit) && it.Next() {
+			result = append(result, it.Transaction())
+		}
+
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		return printTransactions(cmd.OutOrStdout(), output, result...)
+	},
+}
+
+var transacoesGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Consultar transação",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		options, err := clientOptions()
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return &transactions.InvalidValueError{ValueParam: "id", Value: args[0]}
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+
+		transaction, err := transactions.NewClient(options...).GetTransactionContext(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		return printTransactions(cmd.OutOrStdout(), output, *transaction)
+	},
+}
+
+// printTransactions writes the transactions as an indented JSON array or as
+// a table with the main fields.
+func printTransactions(w io.Writer, output string, values ...transactions.TransactionResponse) error {
+	switch output {
+	case "json":
+		return printJSON(w, values, []transactions.TransactionResponse{})
+	case "table":
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tSTATUS\tMETODO\tVALOR\tCRIADA EM")
+		for _, row := range values {
+			fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\n", row.ID, row.Status, row.PaymentMethod, transactions.Money(row.Amount).Decimal(), row.DateCreated.Format("2006-01-02 15:04"))
+		}
+		return table.Flush()
+	}
+
+	return &transactions.InvalidValueError{ValueParam: "output", Value: output}
+}
+
+// printResult writes the transaction created or changed by a command as
+// printTransactions, followed in the table by the URL and the barcode of a
+// boleto.
+func printResult(w io.Writer, output string, transaction transactions.TransactionResponse) error {
+	if err := printTransactions(w, output, transaction); err != nil {
+		return err
+	}
+
+	if output == "table" && transaction.BoletoURL != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Boleto:", transaction.BoletoURL)
+		fmt.Fprintln(w, "Código de barras:", transaction.BoletoBarcode)
+	}
+	return nil
+}
+
+// outputFlag returns --output, checked before a command sends any request.
+func outputFlag(cmd *cobra.Command) (string, error) {
+	output, _ := cmd.Flags().GetString("output")
+	if output != "table" && output != "json" {
+		return "", &transactions.InvalidValueError{ValueParam: "output", Value: output}
+	}
+	return output, nil
+}
+
+func init() {
+	rootCmd.AddCommand(transacoesCmd)
+	transacoesCmd.AddCommand(transacoesListCmd)
+	transacoesCmd.AddCommand(transacoesGetCmd)
+	transacoesCmd.PersistentFlags().StringP("output", "o", "table", "Output format: table or json")
+
+	transacoesListCmd.Flags().StringP("status", "s", "", "Status (paid, refused, waiting_payment...)")
+	transacoesListCmd.Flags().StringP("paymentMethod", "m", "", "Payment method (credit_card or boleto)")
+	transacoesListCmd.Flags().String("from", "", "Created from date (YYYY-MM-DD)")
+	transacoesListCmd.Flags().String("to", "", "Created until date (YYYY-MM-DD)")
+	transacoesListCmd.Flags().StringP("document", "d", "", "Customer document number")
+	transacoesListCmd.Flags().StringSlice("metadata", nil, "Metadata filter key=value (repeatable)")
+	transacoesListCmd.Flags().IntP("limit", "l", 0, "Maximum number of transactions (default all)")
+}
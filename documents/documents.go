@@ -0,0 +1,147 @@
+// Package documents validates Brazilian CPF and CNPJ numbers by their
+// check digits, accepting them formatted or not.
+//
+// CNPJ numbers may also use the alphanumeric format, where the first twelve
+// characters are digits or upper case letters and only the two check digits
+// are numeric.
+package documents
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DocumentType int
+
+const (
+	CPF DocumentType = iota
+	CNPJ
+)
+
+const CPF_LENGTH = 11
+const CNPJ_LENGTH = 14
+
+func (d DocumentType) String() string {
+	return [...]string{"cpf", "cnpj"}[d]
+}
+
+type InvalidDocumentError struct {
+	Value  string
+	Reason string
+}
+
+func (e *InvalidDocumentError) Error() string {
+	return fmt.Sprintf("Document %v is invalid: %v", e.Value, e.Reason)
+}
+
+// Normalize removes the formatting of a document, the dots, dash, slash and
+// spaces, and upper cases the letters of an alphanumeric CNPJ.
+func Normalize(value string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '/', ' ':
+			return -1
+		}
+		return r
+	}, value))
+}
+
+// Validate checks a CPF or CNPJ, detected by its length, and returns its
+// type and normalized number.
+func Validate(value string) (DocumentType, string, error) {
+	number := Normalize(value)
+
+	switch len(number) {
+	case CPF_LENGTH:
+		return CPF, number, ValidateCPF(value)
+	case CNPJ_LENGTH:
+		return CNPJ, number, ValidateCNPJ(value)
+	}
+
+	return 0, number, &InvalidDocumentError{value, "length is not of a CPF or CNPJ"}
+}
+
+// ValidateCPF checks the two mod 11 check digits of a CPF.
+func ValidateCPF(value string) error {
+	number := Normalize(value)
+
+	if len(number) != CPF_LENGTH || !isDigits(number) {
+		return &InvalidDocumentError{value, "CPF must have 11 digits"}
+	}
+
+	if repeated(number) {
+		return &InvalidDocumentError{value, "repeated digits"}
+	}
+
+	first := checkDigit(number[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(number[:9]+string(first), []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+
+	if number[9] != first || number[10] != second {
+		return &InvalidDocumentError{value, "wrong check digits"}
+	}
+
+	return nil
+}
+
+// ValidateCNPJ checks the two mod 11 check digits of a numeric or
+// alphanumeric CNPJ.
+func ValidateCNPJ(value string) error {
+	number := Normalize(value)
+
+	if len(number) != CNPJ_LENGTH || !isAlphanumeric(number[:12]) || !isDigits(number[12:]) {
+		return &InvalidDocumentError{value, "CNPJ must have 12 letters or digits and 2 check digits"}
+	}
+
+	if repeated(number) {
+		return &InvalidDocumentError{value, "repeated digits"}
+	}
+
+	first := checkDigit(number[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := checkDigit(number[:12]+string(first), []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+
+	if number[12] != first || number[13] != second {
+		return &InvalidDocumentError{value, "wrong check digits"}
+	}
+
+	return nil
+}
+
+// checkDigit computes a mod 11 check digit. Characters are valued by their
+// ASCII code minus 48, so digits keep their value and the letters of an
+// alphanumeric CNPJ go from 17 ('A') to 42 ('Z').
+func checkDigit(value string, weights []int) byte {
+	sum := 0
+	for i := range weights {
+		sum += int(value[i]-'0') * weights[i]
+	}
+
+	remainder := sum % 11
+	if remainder < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - remainder)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// repeated reports sequences such as 111.111.111-11, which have valid check
+// digits but are not issued.
+func repeated(value string) bool {
+	return strings.Count(value, value[:1]) == len(value)
+}
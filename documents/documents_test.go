@@ -0,0 +1,65 @@
+package documents
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		value        string
+		documentType DocumentType
+		number       string
+	}{
+		{"251.854.650-26", CPF, "25185465026"},
+		{"25185465026", CPF, "25185465026"},
+		{"30.516.297/0001-03", CNPJ, "30516297000103"},
+		{"30516297000103", CNPJ, "30516297000103"},
+		{"12.ABC.345/01DE-35", CNPJ, "12ABC34501DE35"},
+		{"12abc34501de35", CNPJ, "12ABC34501DE35"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			documentType, number, err := Validate(test.value)
+
+			assertTest := assert.New(t)
+			assertTest.Nil(err)
+			assertTest.Equal(test.documentType, documentType)
+			assertTest.Equal(test.number, number)
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	tests := []struct {
+		value  string
+		reason string
+	}{
+		{"000.000.000-00", "repeated digits"},
+		{"111.111.111-11", "repeated digits"},
+		{"00.000.000/0000-00", "repeated digits"},
+		{"251.854.650-27", "wrong check digits"},
+		{"30.516.297/0001-04", "wrong check digits"},
+		{"12.ABC.345/01DE-36", "wrong check digits"},
+		{"x123.456.789-00y", "length is not of a CPF or CNPJ"},
+		{"2518546502A", "CPF must have 11 digits"},
+		{"12.ABC.345/01DE-3A", "CNPJ must have 12 letters or digits and 2 check digits"},
+		{"", "length is not of a CPF or CNPJ"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			_, _, err := Validate(test.value)
+
+			assertTest := assert.New(t)
+			assertTest.EqualError(err, "Document "+test.value+" is invalid: "+test.reason)
+		})
+	}
+}
+
+func TestDocumentTypeString(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("cpf", CPF.String())
+	assertTest.Equal("cnpj", CNPJ.String())
+}
@@ -0,0 +1,124 @@
+package pagarmetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"pagarme/transactions"
+	"strconv"
+	"time"
+)
+
+// storedCard is a card saved by POST /cards or by a paid transaction, which
+// can be charged again by its id.
+type storedCard struct {
+	card       *transactions.Card
+	number     string
+	customerID int
+}
+
+type cardRequest struct {
+	CustomerID int    `json:"customer_id"`
+	CardHash   string `json:"card_hash"`
+}
+
+func (s *Server) serveCards(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.createCard(w, r)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.listCards(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.getCard(w, parts[0])
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+func (s *Server) createCard(w http.ResponseWriter, r *http.Request) {
+	request := cardRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.decryptCardHash(request.CardHash)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "card_hash", "card_hash inválido")
+		return
+	}
+	if card.Get("card_number") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "card_number", "Número do cartão está faltando")
+		return
+	}
+	if _, ok := s.customers[request.CustomerID]; request.CustomerID != 0 && !ok {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "customer_id", "Customer not found")
+		return
+	}
+
+	s.nextID++
+	stored := s.storeCard(newCard(fmt.Sprintf("card_%v", s.nextID), card, time.Now().UTC()), card, request.CustomerID)
+	writeJSON(w, http.StatusOK, stored.card)
+}
+
+// storeCard saves card to be charged by its id. It must be called with s.mu
+// held.
+func (s *Server) storeCard(card *transactions.Card, values url.Values, customerID int) *storedCard {
+	stored := &storedCard{card: card, number: values.Get("card_number"), customerID: customerID}
+	if _, ok := s.cards[card.ID]; !ok {
+		s.cardOrder = append(s.cardOrder, card.ID)
+	}
+	s.cards[card.ID] = stored
+	return stored
+}
+
+// cardValues returns the fields of the stored card id, with the cvv sent
+// with the transaction. It must be called with s.mu held.
+func (s *Server) cardValues(id string, cvv string) (url.Values, bool) {
+	stored, ok := s.cards[id]
+	if !ok {
+		return nil, false
+	}
+
+	return url.Values{
+		"card_number":          {stored.number},
+		"card_holder_name":     {stored.card.HolderName},
+		"card_expiration_date": {stored.card.ExpirationDate},
+		"card_cvv":             {cvv},
+	}, true
+}
+
+func (s *Server) getCard(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.cards[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Card not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stored.card)
+}
+
+func (s *Server) listCards(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	customerID, _ := strconv.Atoi(q.Get("customer_id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []*transactions.Card{}
+	for i := len(s.cardOrder) - 1; i >= 0; i-- {
+		stored := s.cards[s.cardOrder[i]]
+		if customerID == 0 || customerID == stored.customerID {
+			found = append(found, stored.card)
+		}
+	}
+
+	start, end := pageBounds(q, len(found))
+	writeJSON(w, http.StatusOK, found[start:end])
+}
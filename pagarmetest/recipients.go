@@ -0,0 +1,316 @@
+package pagarmetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"pagarme/documents"
+	"pagarme/transactions"
+	"strconv"
+	"time"
+)
+
+// recipientRequest mirrors the bodies sent by transactions.client to create
+// and update recipients. Absent fields are nil and left unchanged.
+type recipientRequest struct {
+	BankAccountID                 int                       `json:"bank_account_id"`
+	BankAccount                   *transactions.BankAccount `json:"bank_account"`
+	TransferEnabled               *bool                     `json:"transfer_enabled"`
+	TransferInterval              *string                   `json:"transfer_interval"`
+	TransferDay                   *int                      `json:"transfer_day"`
+	AutomaticAnticipationEnabled  *bool                     `json:"automatic_anticipation_enabled"`
+	AutomaticAnticipationType     *string                   `json:"automatic_anticipation_type"`
+	AutomaticAnticipationDays     []int                     `json:"automatic_anticipation_days"`
+	AnticipatableVolumePercentage *int                      `json:"anticipatable_volume_percentage"`
+	PostbackURL                   string                    `json:"postback_url"`
+	Metadata                      map[string]interface{}    `json:"metadata"`
+}
+
+func (s *Server) serveRecipients(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.createRecipient(w, r)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.listRecipients(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.getRecipient(w, parts[0])
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		s.updateRecipient(w, r, parts[0])
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+func (s *Server) serveBankAccounts(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.createBankAccount(w, r)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.listBankAccounts(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		id, _ := strconv.Atoi(parts[0])
+		s.getBankAccount(w, id)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+func (s *Server) createBankAccount(w http.ResponseWriter, r *http.Request) {
+	request := transactions.BankAccount{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, parameter := s.newBankAccount(request)
+	if account == nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", parameter, "valor inválido")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, account)
+}
+
+// newBankAccount stores request, or returns the name of its first invalid
+// parameter. It must be called with s.mu held.
+func (s *Server) newBankAccount(request transactions.BankAccount) (*transactions.BankAccount, string) {
+	documentType, number, err := documents.Validate(request.DocumentNumber)
+	switch {
+	case len(request.BankCode) != 3:
+		return nil, "bank_code"
+	case request.Agencia == "":
+		return nil, "agencia"
+	case request.Conta == "":
+		return nil, "conta"
+	case request.ContaDv == "":
+		return nil, "conta_dv"
+	case err != nil:
+		return nil, "document_number"
+	case request.LegalName == "":
+		return nil, "legal_name"
+	}
+
+	now := time.Now().UTC()
+	s.nextID++
+	account := request
+	account.Object = "bank_account"
+	account.ID = s.nextID
+	account.DocumentNumber = number
+	account.DocumentType = documentType.String()
+	account.DateCreated = &now
+	if account.Type == "" {
+		account.Type = string(transactions.CONTA_CORRENTE)
+	}
+
+	s.bankAccounts[account.ID] = &account
+	s.bankAccountOrder = append(s.bankAccountOrder, account.ID)
+	return &account, ""
+}
+
+func (s *Server) getBankAccount(w http.ResponseWriter, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.bankAccounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Bank account not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, account)
+}
+
+func (s *Server) listBankAccounts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []*transactions.BankAccount{}
+	for i := len(s.bankAccountOrder) - 1; i >= 0; i-- {
+		account := s.bankAccounts[s.bankAccountOrder[i]]
+		if document := q.Get("document_number"); document == "" || document == account.DocumentNumber {
+			found = append(found, account)
+		}
+	}
+
+	start, end := pageBounds(q, len(found))
+	writeJSON(w, http.StatusOK, found[start:end])
+}
+
+func (s *Server) createRecipient(w http.ResponseWriter, r *http.Request) {
+	request := recipientRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, parameter := s.recipientBankAccount(request)
+	if account == nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", parameter, "Conta bancária inválida")
+		return
+	}
+
+	now := time.Now().UTC()
+	s.nextID++
+	recipient := &transactions.RecipientResponse{
+		Object:      "recipient",
+		ID:          fmt.Sprintf("re_%v", s.nextID),
+		Status:      "active",
+		BankAccount: *account,
+		Metadata:    map[string]interface{}{},
+		DateCreated: now,
+	}
+	recipient.TransferInterval = transactions.WEEKLY
+	recipient.TransferDay = 5
+	recipient.TransferEnabled = true
+
+	update(recipient, request, now)
+	s.recipients[recipient.ID] = recipient
+	s.recipientOrder = append(s.recipientOrder, recipient.ID)
+
+	writeJSON(w, http.StatusOK, recipient)
+}
+
+// recipientBankAccount returns the existing or new bank account of request,
+// or the name of the invalid parameter. It must be called with s.mu held.
+func (s *Server) recipientBankAccount(request recipientRequest) (*transactions.BankAccount, string) {
+	if request.BankAccount != nil {
+		return s.newBankAccount(*request.BankAccount)
+	}
+
+	account, ok := s.bankAccounts[request.BankAccountID]
+	if !ok {
+		return nil, "bank_account_id"
+	}
+	return account, ""
+}
+
+func (s *Server) getRecipient(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipient, ok := s.recipients[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Recipient not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recipient)
+}
+
+func (s *Server) listRecipients(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := make([]*transactions.RecipientResponse, 0, len(s.recipientOrder))
+	for i := len(s.recipientOrder) - 1; i >= 0; i-- {
+		found = append(found, s.recipients[s.recipientOrder[i]])
+	}
+
+	start, end := pageBounds(r.URL.Query(), len(found))
+	writeJSON(w, http.StatusOK, found[start:end])
+}
+
+// updateRecipient changes the fields present in the body. As Pagar.me, it
+// refuses a bank account of another document.
+func (s *Server) updateRecipient(w http.ResponseWriter, r *http.Request, id string) {
+	request := recipientRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recipient, ok := s.recipients[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Recipient not found")
+		return
+	}
+
+	if request.BankAccount != nil || request.BankAccountID != 0 {
+		if request.BankAccount != nil {
+			number := documents.Normalize(request.BankAccount.DocumentNumber)
+			if number != recipient.BankAccount.DocumentNumber {
+				writeError(w, http.StatusBadRequest, "invalid_parameter", "bank_account", "A conta bancária deve ter o mesmo documento do recebedor")
+				return
+			}
+		}
+
+		account, parameter := s.recipientBankAccount(request)
+		if account == nil {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", parameter, "Conta bancária inválida")
+			return
+		}
+		if account.DocumentNumber != recipient.BankAccount.DocumentNumber {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", "bank_account_id", "A conta bancária deve ter o mesmo documento do recebedor")
+			return
+		}
+		recipient.BankAccount = *account
+	}
+
+	update(recipient, request, time.Now().UTC())
+	writeJSON(w, http.StatusOK, recipient)
+}
+
+func update(recipient *transactions.RecipientResponse, request recipientRequest, now time.Time) {
+	if request.TransferEnabled != nil {
+		recipient.TransferEnabled = *request.TransferEnabled
+	}
+	if request.TransferInterval != nil {
+		recipient.TransferInterval = transactions.TransferInterval(*request.TransferInterval)
+	}
+	if request.TransferDay != nil {
+		recipient.TransferDay = *request.TransferDay
+	}
+	if request.AutomaticAnticipationEnabled != nil {
+		recipient.AutomaticAnticipationEnabled = *request.AutomaticAnticipationEnabled
+	}
+	if request.AutomaticAnticipationType != nil {
+		recipient.AutomaticAnticipationType = transactions.AnticipationType(*request.AutomaticAnticipationType)
+	}
+	if request.AutomaticAnticipationDays != nil {
+		recipient.AutomaticAnticipationDays = request.AutomaticAnticipationDays
+	}
+	if request.AnticipatableVolumePercentage != nil {
+		recipient.AnticipatableVolumePercentage = *request.AnticipatableVolumePercentage
+	}
+	if request.PostbackURL != "" {
+		recipient.PostbackURL = request.PostbackURL
+	}
+	for key, value := range request.Metadata {
+		recipient.Metadata[key] = value
+	}
+	recipient.DateUpdated = now
+}
+
+// pageBounds returns the slice bounds of the count and page query
+// parameters in a list of length elements.
+func pageBounds(q url.Values, length int) (int, int) {
+	count, _ := strconv.Atoi(q.Get("count"))
+	if count <= 0 {
+		count = 10
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * count
+	if start > length {
+		start = length
+	}
+	end := start + count
+	if end > length {
+		end = length
+	}
+	return start, end
+}
@@ -0,0 +1,376 @@
+// Package pagarmetest provides a stateful in-process emulator of the
+// Pagar.me API for development and integration tests.
+//
+//	server := pagarmetest.NewServer("ak_test_key")
+//	defer server.Close()
+//
+//	client := transactions.NewClient(
+//		transactions.WithAPIKey("ak_test_key"),
+//		transactions.WithBaseURL(server.URL),
+//	)
+//
+// Card hashes are decrypted with the server own RSA key, served by
+// /transactions/card_hash_key. Results are simulated from the card data:
+//
+//   - card number REFUSED_CARD_NUMBER or a CVV starting with 6 is refused
+//     by the acquirer;
+//   - an amount whose cents are ANTIFRAUD_REFUSED_CENTS (R$ 10,13) is
+//     refused by the antifraud;
+//   - any other card transaction is paid, or authorized when capture is false.
+//
+// Boletos are created as waiting_payment and paid with Server.Pay.
+// Recipients only accept a new bank account of the same document, as in
+// Pagar.me. Cards of paid transactions and of POST /cards are stored and can
+// be charged again by card_id.
+package pagarmetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pagarme/transactions"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const REFUSED_CARD_NUMBER = "4000000000000010"
+const ANTIFRAUD_REFUSED_CENTS = 13
+const BOLETO_URL = "https://pagar.me"
+const BOLETO_BARCODE = "23790.00000 00000.000000 00000.000000 0 00000000000000"
+
+var privateKey *rsa.PrivateKey
+var privateKeyOnce sync.Once
+
+// key returns the RSA key shared by every server. It is generated once per
+// process because generating it is slow.
+func key() *rsa.PrivateKey {
+	privateKeyOnce.Do(func() {
+		privateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	})
+	return privateKey
+}
+
+// Server emulates the Pagar.me API. Every method is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of a server started by NewServer.
+	URL string
+
+	apiKey        string
+	encryptionKey string
+	keyID         int
+
+	mu           sync.Mutex
+	nextID       int
+	transactions map[int]*transactions.TransactionResponse
+	order        []int
+	customers    map[int]*transactions.CustomerResponse
+	postbackURLs map[int]string
+	requests     map[string]int
+
+	recipients       map[string]*transactions.RecipientResponse
+	recipientOrder   []string
+	bankAccounts     map[int]*transactions.BankAccount
+	bankAccountOrder []int
+	cards            map[string]*storedCard
+	cardOrder        []string
+
+	postbacks  sync.WaitGroup
+	httpClient *http.Client
+	httpServer *httptest.Server
+}
+
+// New returns a server, as an http.Handler, that accepts apiKey.
+func New(apiKey string) *Server {
+	return &Server{
+		apiKey:       apiKey,
+		keyID:        1,
+		nextID:       1000,
+		transactions: map[int]*transactions.TransactionResponse{},
+		customers:    map[int]*transactions.CustomerResponse{},
+		postbackURLs: map[int]string{},
+		recipients:   map[string]*transactions.RecipientResponse{},
+		bankAccounts: map[int]*transactions.BankAccount{},
+		cards:        map[string]*storedCard{},
+		requests:     map[string]int{},
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewServer starts a server listening on a local address, available in URL.
+func NewServer(apiKey string) *Server {
+	s := New(apiKey)
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// SetEncryptionKey makes the server also accept encryptionKey on
+// /transactions/card_hash_key.
+func (s *Server) SetEncryptionKey(encryptionKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encryptionKey = encryptionKey
+}
+
+// RotateKey changes the id of the key served by /transactions/card_hash_key,
+// so card hashes made with the previous key are rejected.
+func (s *Server) RotateKey() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyID++
+}
+
+// Close waits for the postbacks being sent and stops a started server.
+func (s *Server) Close() {
+	s.postbacks.Wait()
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// Requests returns how many requests were received for method and path,
+// such as Requests("GET", "/transactions/card_hash_key").
+func (s *Server) Requests(method string, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+path]
+}
+
+// Transaction returns a copy of a transaction stored by the server.
+func (s *Server) Transaction(id int) (transactions.TransactionResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		return transactions.TransactionResponse{}, false
+	}
+	return *transaction, true
+}
+
+// Pay marks a boleto waiting payment as paid and sends its postback.
+func (s *Server) Pay(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		return fmt.Errorf("transaction %v not found", id)
+	}
+	if transaction.Status != transactions.WAITING_PAYMENT {
+		return fmt.Errorf("transaction %v is %v", id, transaction.Status)
+	}
+
+	transaction.PaidAmount = transaction.Amount
+	s.setStatus(transaction, transactions.PAID)
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	s.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == transactions.PATH_HASH:
+		s.cardHashKey(w, r)
+	case !s.authenticated(r):
+		writeError(w, http.StatusUnauthorized, "action_forbidden", "", "api_key inválida")
+	case parts[0] == "transactions":
+		s.serveTransactions(w, r, parts[1:])
+	case parts[0] == "customers":
+		s.serveCustomers(w, r, parts[1:])
+	case parts[0] == "recipients":
+		s.serveRecipients(w, r, parts[1:])
+	case parts[0] == "bank_accounts":
+		s.serveBankAccounts(w, r, parts[1:])
+	case parts[0] == "cards":
+		s.serveCards(w, r, parts[1:])
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+func (s *Server) serveTransactions(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodPost:
+			s.createTransaction(w, r)
+		case http.MethodGet:
+			s.listTransactions(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "not_found", "", "Método não permitido")
+		}
+		return
+	}
+
+	if len(parts) == 1 && parts[0] == "calculate_installments_amount" && r.Method == http.MethodGet {
+		s.calculateInstallments(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", "", "Transaction not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.getTransaction(w, id)
+	case len(parts) == 2 && parts[1] == "capture" && r.Method == http.MethodPost:
+		s.capture(w, r, id)
+	case len(parts) == 2 && parts[1] == "refund" && r.Method == http.MethodPost:
+		s.refund(w, r, id)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+func (s *Server) serveCustomers(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		s.createCustomer(w, r)
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		s.listCustomers(w, r)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		id, _ := strconv.Atoi(parts[0])
+		s.getCustomer(w, id)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "", "Rota não encontrada")
+	}
+}
+
+// authenticated accepts the api_key sent with any of the
+// transactions.AuthenticationMethod: basic auth, query parameter or body.
+func (s *Server) authenticated(r *http.Request) bool {
+	if user, _, ok := r.BasicAuth(); ok {
+		return user == s.apiKey
+	}
+
+	if key := r.URL.Query().Get("api_key"); key != "" {
+		return key == s.apiKey
+	}
+
+	if r.Body == nil || r.Method == http.MethodGet {
+		return false
+	}
+
+	body := map[string]interface{}{}
+	data, err := readBody(r)
+	if err != nil {
+		return false
+	}
+	json.Unmarshal(data, &body)
+
+	return body["api_key"] == s.apiKey
+}
+
+func (s *Server) cardHashKey(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	encryptionKey := s.encryptionKey
+	keyID := s.keyID
+	s.mu.Unlock()
+
+	q := r.URL.Query()
+	user, _, _ := r.BasicAuth()
+	validKey := q.Get("api_key") == s.apiKey || user == s.apiKey || (encryptionKey != "" && q.Get("encryption_key") == encryptionKey)
+	if !validKey {
+		writeError(w, http.StatusUnauthorized, "action_forbidden", "", "encryption_key inválida")
+		return
+	}
+
+	der, _ := x509.MarshalPKIXPublicKey(&key().PublicKey)
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":           keyID,
+		"public_key":   string(publicKey),
+		"ip":           host,
+		"date_created": time.Now().UTC(),
+	})
+}
+
+// decryptCardHash reverses transaction.CreateCardHash, returning the card
+// fields encrypted in the hash. It must be called with s.mu held.
+func (s *Server) decryptCardHash(cardHash string) (url.Values, bool) {
+	parts := strings.SplitN(cardHash, "_", 2)
+	if len(parts) != 2 || parts[0] != strconv.Itoa(s.keyID) {
+		return nil, false
+	}
+
+	encrypted, err := b64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false
+	}
+
+	decrypted, err := rsa.DecryptPKCS1v15(rand.Reader, key(), encrypted)
+	if err != nil {
+		return nil, false
+	}
+
+	card, err := url.ParseQuery(string(decrypted))
+	if err != nil {
+		return nil, false
+	}
+
+	return card, true
+}
+
+// readBody reads the request body, leaving it available to be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+func decodeBody(r *http.Request, out interface{}) error {
+	data, err := readBody(r)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError answers with the errors array documented by Pagar.me, decoded
+// by the client into transactions.APIError.
+func writeError(w http.ResponseWriter, status int, errorType string, parameterName string, message string) {
+	var parameter interface{}
+	if parameterName != "" {
+		parameter = parameterName
+	}
+
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"type":           errorType,
+			"parameter_name": parameter,
+			"message":        message,
+		}},
+	})
+}
@@ -0,0 +1,544 @@
+package pagarmetest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"pagarme/postback"
+	"pagarme/transactions"
+	"strings"
+	"testing"
+	"time"
+)
+
+const apiKey = "ak_test_key"
+
+func cardTransaction(cardNumber string, cvv string, amount transactions.Money, capture bool) *transactions.TransactionBuilder {
+	builder := &transactions.TransactionBuilder{}
+	builder.Amount(amount).PaymentMethod(transactions.CREDIT_CARD).Capture(capture)
+	builder.CardNumber(cardNumber)
+	builder.CardHolderName("Leandro")
+	builder.CardExpirationDate("1030")
+	builder.CardCVV(cvv)
+	builder.Name("Leandro")
+	builder.Document("251.854.650-26")
+	return builder
+}
+
+func boletoTransaction(amount transactions.Money) *transactions.TransactionBuilder {
+	builder := &transactions.TransactionBuilder{}
+	builder.Amount(amount).PaymentMethod(transactions.BOLETO)
+	builder.Name("Leandro")
+	builder.Document("251.854.650-26")
+	return builder
+}
+
+func build(t *testing.T, builder *transactions.TransactionBuilder) transactions.Transaction {
+	transaction, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction
+}
+
+func TestCardHashPaid(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	transaction := build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true))
+	key, err := client.RecoverPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTest := assert.New(t)
+	assertTest.Nil(transaction.CreateCardHash(key))
+
+	result, err := client.Execute(transaction, transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, result.Status)
+	assertTest.Equal(int64(3300), result.PaidAmount)
+	assertTest.Equal(transactions.VISA, result.CardBrand)
+	assertTest.Equal("1111", result.CardLastDigits)
+	assertTest.Equal(transaction.IdempotencyKey(), result.Metadata[transactions.METADATA_IDEMPOTENCY_KEY])
+	assertTest.Equal(1, server.Requests(http.MethodGet, transactions.PATH_HASH))
+}
+
+func TestCardHashInvalid(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	transaction := build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true))
+	transaction.CardHash = "1_invalid"
+	result, err := client.Execute(transaction, transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	apiError, ok := err.(*transactions.APIError)
+	assertTest.True(ok)
+	_, found := apiError.Field("card_hash")
+	assertTest.True(found)
+}
+
+func TestCardHashKeyReused(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	assertTest := assert.New(t)
+
+	for i := 0; i < 3; i++ {
+		result, err := client.ExecuteWithCardHash(build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true)), transactions.BASIC_AUTH)
+		assertTest.Nil(err)
+		assertTest.Equal(transactions.PAID, result.Status)
+	}
+
+	assertTest.Equal(1, server.Requests(http.MethodGet, transactions.PATH_HASH))
+	assertTest.Equal(3, server.Requests(http.MethodPost, transactions.PATH_TRANSACTION))
+}
+
+func TestCardHashKeyRotated(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	assertTest := assert.New(t)
+
+	_, err := client.ExecuteWithCardHash(build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true)), transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+
+	server.RotateKey()
+
+	result, err := client.ExecuteWithCardHash(build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true)), transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, result.Status)
+	assertTest.Equal(2, server.Requests(http.MethodGet, transactions.PATH_HASH))
+	assertTest.Equal(3, server.Requests(http.MethodPost, transactions.PATH_TRANSACTION))
+}
+
+func TestInstallments(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	assertTest := assert.New(t)
+
+	options := transactions.InstallmentOptions{
+		Amount:           transactions.NewMoney(100, 0),
+		MaxInstallments:  12,
+		FreeInstallments: 2,
+		InterestRate:     199,
+	}
+
+	installments, err := client.CalculateInstallments(options)
+	assertTest.Nil(err)
+	// amount and installment amount of 1 to 12 installments
+	expected := [][2]transactions.Money{
+		{10000, 10000}, {10000, 5000}, {10597, 3532}, {10796, 2699},
+		{10995, 2199}, {11194, 1866}, {11393, 1628}, {11592, 1449},
+		{11791, 1310}, {11990, 1199}, {12189, 1108}, {12388, 1032},
+	}
+	assertTest.Len(installments, len(expected))
+	for i, installment := range installments {
+		assertTest.Equal(i+1, installment.Installment)
+		assertTest.Equal(expected[i], [2]transactions.Money{installment.Amount, installment.InstallmentAmount})
+	}
+	offline, _ := transactions.CalculateInstallments(options)
+	assertTest.Equal(offline, installments)
+
+	builder := cardTransaction("4111111111111111", "123", installments[5].Amount, true)
+	builder.Installments(installments[5].Installment)
+	result, err := client.ExecuteWithCardHash(build(t, builder), transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+	assertTest.Equal(6, result.Installments)
+	assertTest.Equal(int64(11194), result.Amount)
+}
+
+func TestRefused(t *testing.T) {
+	tests := []struct {
+		name       string
+		cardNumber string
+		cvv        string
+		amount     transactions.Money
+		reason     string
+	}{
+		{"acquirer card", REFUSED_CARD_NUMBER, "123", transactions.NewMoney(33, 0), "acquirer"},
+		{"acquirer cvv", "4111111111111111", "612", transactions.NewMoney(33, 0), "acquirer"},
+		{"antifraud", "4111111111111111", "123", transactions.NewMoney(10, 13), "antifraud"},
+	}
+
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := client.Execute(build(t, cardTransaction(test.cardNumber, test.cvv, test.amount, true)), transactions.BODY)
+
+			assertTest := assert.New(t)
+			assertTest.Nil(err)
+			assertTest.Equal(transactions.REFUSED, result.Status)
+			assertTest.Equal(test.reason, result.RefuseReason)
+		})
+	}
+}
+
+func TestCaptureAndRefund(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	assertTest := assert.New(t)
+
+	result, err := client.Execute(build(t, cardTransaction("5555555555554444", "123", transactions.NewMoney(100, 0), false)), transactions.PARAM)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.AUTHORIZED, result.Status)
+
+	_, err = client.Capture(result.ID, transactions.NewMoney(150, 0))
+	assertTest.EqualError(err, "Pagar.me error. Status: 400 Path: /transactions/1001/capture. Errors: amount: Valor acima do autorizado")
+
+	captured, err := client.Capture(result.ID, transactions.NewMoney(80, 0))
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, captured.Status)
+	assertTest.Equal(int64(8000), captured.PaidAmount)
+
+	refunded, err := client.Refund(result.ID, transactions.NewMoney(30, 0))
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, refunded.Status)
+	assertTest.Equal(int64(3000), refunded.RefundedAmount)
+
+	refunded, err = client.Refund(result.ID, 0)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.REFUNDED, refunded.Status)
+	assertTest.Equal(int64(8000), refunded.RefundedAmount)
+
+	_, err = client.Refund(result.ID, 0)
+	assertTest.NotNil(err)
+}
+
+func TestBoletoPayAndPostback(t *testing.T) {
+	server := NewServer(apiKey)
+
+	events := make(chan postback.TransactionEvent, 2)
+	handler := postback.NewHandler(apiKey)
+	handler.OnTransaction(func(event postback.TransactionEvent) error {
+		events <- event
+		return nil
+	})
+	receiver := httptest.NewServer(handler)
+	defer receiver.Close()
+
+	client := transactions.NewClient(
+		transactions.WithAPIKey(apiKey),
+		transactions.WithBaseURL(server.URL),
+		transactions.WithPollInterval(10*time.Millisecond, 10*time.Millisecond),
+	)
+
+	builder := boletoTransaction(transactions.NewMoney(33, 0))
+	builder.PostbackURL(receiver.URL)
+	result, err := client.Execute(build(t, builder), transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.WAITING_PAYMENT, result.Status)
+	assertTest.Equal(BOLETO_URL, result.BoletoURL)
+	assertTest.NotNil(result.BoletoExpirationDate)
+
+	assertTest.Nil(server.Pay(result.ID))
+	assertTest.NotNil(server.Pay(result.ID))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	paid, err := client.WaitForStatus(ctx, result.ID, transactions.PAID)
+	assertTest.Nil(err)
+	assertTest.Equal(int64(3300), paid.PaidAmount)
+
+	server.Close()
+	close(events)
+
+	statuses := []transactions.Status{}
+	for event := range events {
+		assertTest.Equal(result.ID, event.Transaction.ID)
+		statuses = append(statuses, event.Transaction.Status)
+	}
+	assertTest.Equal([]transactions.Status{transactions.WAITING_PAYMENT, transactions.PAID}, statuses)
+}
+
+func TestBoletoExpirationDate(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	expiration := transactions.AddBusinessDays(time.Now(), 3)
+	builder := boletoTransaction(transactions.NewMoney(50, 0))
+	builder.BoletoExpirationDate(expiration)
+	builder.BoletoFine(transactions.BoletoCharge{Days: 1, Percentage: 200})
+	result, err := client.Execute(build(t, builder), transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(expiration.Format(transactions.BOLETO_DATE_LAYOUT), result.BoletoExpirationDate.Format(transactions.BOLETO_DATE_LAYOUT))
+}
+
+func TestSplitRules(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	builder := boletoTransaction(transactions.NewMoney(50, 0))
+	builder.SplitRules(
+		transactions.SplitRule{RecipientID: "re_1", Percentage: 70, Liable: true, ChargeRemainderFee: true},
+		transactions.SplitRule{RecipientID: "re_2", Percentage: 30},
+	)
+	result, err := client.Execute(build(t, builder), transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Len(result.SplitRules, 2)
+	assertTest.Equal("re_1", result.SplitRules[0].RecipientID)
+	assertTest.Equal(70, result.SplitRules[0].Percentage)
+	assertTest.True(result.SplitRules[0].ChargeRemainder)
+	assertTest.False(result.SplitRules[1].ChargeRemainder)
+}
+
+func TestBoletoRefundRequiresBankAccount(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	result, _ := client.Execute(build(t, boletoTransaction(transactions.NewMoney(33, 0))), transactions.BASIC_AUTH)
+	server.Pay(result.ID)
+
+	account := transactions.BankAccount{
+		BankCode:       "341",
+		Agencia:        "0057",
+		Conta:          "72192",
+		ContaDv:        "0",
+		DocumentNumber: "25185465026",
+		LegalName:      "Leandro",
+	}
+	refunded, err := client.RefundBoleto(result.ID, 0, account)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PENDING_REFUND, refunded.Status)
+
+	stored, ok := server.Transaction(result.ID)
+	assertTest.True(ok)
+	assertTest.Equal(int64(3300), stored.RefundedAmount)
+}
+
+func TestListAndGet(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+	card, _ := client.Execute(build(t, cardTransaction("4111111111111111", "123", transactions.NewMoney(33, 0), true)), transactions.BASIC_AUTH)
+	boleto, _ := client.Execute(build(t, boletoTransaction(transactions.NewMoney(33, 0))), transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+
+	iterator := client.ListTransactions(transactions.TransactionFilter{Count: 1})
+	ids := []int{}
+	for iterator.Next() {
+		ids = append(ids, iterator.Transaction().ID)
+	}
+	assertTest.Nil(iterator.Err())
+	assertTest.Equal([]int{boleto.ID, card.ID}, ids)
+
+	iterator = client.ListTransactions(transactions.TransactionFilter{Status: transactions.WAITING_PAYMENT})
+	assertTest.True(iterator.Next())
+	assertTest.Equal(boleto.ID, iterator.Transaction().ID)
+	assertTest.False(iterator.Next())
+
+	iterator = client.ListTransactions(transactions.TransactionFilter{
+		Metadata: map[string]string{transactions.METADATA_IDEMPOTENCY_KEY: card.Metadata[transactions.METADATA_IDEMPOTENCY_KEY].(string)},
+	})
+	assertTest.True(iterator.Next())
+	assertTest.Equal(card.ID, iterator.Transaction().ID)
+	assertTest.False(iterator.Next())
+
+	result, err := client.GetTransaction(card.ID)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, result.Status)
+
+	_, err = client.GetTransaction(1)
+	apiError, _ := err.(*transactions.APIError)
+	assertTest.True(apiError.IsNotFound())
+}
+
+func TestUnauthorized(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey("ak_test_other"), transactions.WithBaseURL(server.URL))
+	_, err := client.Execute(build(t, boletoTransaction(transactions.NewMoney(33, 0))), transactions.BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	apiError, ok := err.(*transactions.APIError)
+	assertTest.True(ok)
+	assertTest.True(apiError.IsAuthentication())
+}
+
+func TestCustomers(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	assertTest := assert.New(t)
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/customers", nil)
+	req.SetBasicAuth(apiKey, "x")
+	res, _ := http.DefaultClient.Do(req)
+	assertTest.Equal(http.StatusBadRequest, res.StatusCode)
+
+	rec := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"external_id":"42","name":"Leandro","type":"individual","documents":[{"type":"cpf","number":"25185465026"}]}`))
+	req.SetBasicAuth(apiKey, "x")
+	server.ServeHTTP(rec, req)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Contains(rec.Body.String(), `"external_id":"42"`)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/customers/1001", nil)
+	req.SetBasicAuth(apiKey, "x")
+	server.ServeHTTP(rec, req)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Contains(rec.Body.String(), `"number":"25185465026"`)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/customers/1", nil)
+	req.SetBasicAuth(apiKey, "x")
+	server.ServeHTTP(rec, req)
+	assertTest.Equal(http.StatusNotFound, rec.Code)
+}
+
+func TestRecipients(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	account, err := client.CreateBankAccount(transactions.BankAccount{
+		BankCode: "341", Agencia: "0057", Conta: "72192", ContaDv: "0",
+		DocumentNumber: "251.854.650-26", LegalName: "Leandro Greijal",
+	})
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("cpf", account.DocumentType)
+
+	recipient, err := client.CreateRecipient(transactions.Recipient{
+		BankAccountID:    account.ID,
+		TransferSettings: transactions.TransferSettings{TransferEnabled: true, TransferInterval: transactions.MONTHLY, TransferDay: 15},
+	})
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.MONTHLY, recipient.TransferInterval)
+	assertTest.Equal(account.ID, recipient.BankAccount.ID)
+
+	updated, err := client.UpdateAnticipationSettings(recipient.ID, transactions.AnticipationSettings{
+		AutomaticAnticipationEnabled: true, AutomaticAnticipationType: transactions.FULL, AnticipatableVolumePercentage: 80,
+	})
+	assertTest.Nil(err)
+	assertTest.Equal(80, updated.AnticipatableVolumePercentage)
+	assertTest.Equal(15, updated.TransferDay)
+
+	found, err := client.GetRecipient(recipient.ID)
+	assertTest.Nil(err)
+	assertTest.True(found.AutomaticAnticipationEnabled)
+
+	it := client.ListRecipients()
+	assertTest.True(it.Next())
+	assertTest.Equal(recipient.ID, it.Recipient().ID)
+	assertTest.False(it.Next())
+	assertTest.Nil(it.Err())
+
+	accounts := client.ListBankAccounts("25185465026")
+	assertTest.True(accounts.Next())
+	assertTest.Equal(account.ID, accounts.BankAccount().ID)
+}
+
+func TestRecipientBankAccountOwnership(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	account := transactions.BankAccount{
+		BankCode: "001", Agencia: "0001", AgenciaDv: "9", Conta: "555", ContaDv: "X",
+		DocumentNumber: "251.854.650-26", LegalName: "Leandro Greijal",
+	}
+	recipient, err := client.CreateRecipient(transactions.Recipient{BankAccount: &account})
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+
+	account.Conta = "123456"
+	account.ContaDv = "0"
+	updated, err := client.UpdateRecipientBankAccount(recipient.ID, &account, 0)
+	assertTest.Nil(err)
+	assertTest.Equal("123456", updated.BankAccount.Conta)
+
+	account.DocumentNumber = "111.444.777-35"
+	_, err = client.UpdateRecipientBankAccount(recipient.ID, &account, 0)
+	_, ok := err.(*transactions.DocumentOwnershipError)
+	assertTest.True(ok)
+}
+
+func TestCardsChargedByCardID(t *testing.T) {
+	server := NewServer(apiKey)
+	defer server.Close()
+
+	client := transactions.NewClient(transactions.WithAPIKey(apiKey), transactions.WithBaseURL(server.URL))
+
+	customer, err := client.CreateCustomer(transactions.Customer{ExternalID: "42", Name: "Leandro", Document: "251.854.650-26"})
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+
+	card, err := client.CreateCardWithCardHash(customer.ID, transactions.CardData{
+		Number: "5555555555554444", HolderName: "Leandro", ExpirationDate: "10/30", CVV: "123",
+	})
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.MASTERCARD, card.Brand)
+	assertTest.Equal("4444", card.LastDigits)
+
+	found, err := client.GetCard(card.ID)
+	assertTest.Nil(err)
+	assertTest.Equal(card.ID, found.ID)
+
+	cards := client.ListCards(customer.ID)
+	assertTest.True(cards.Next())
+	assertTest.Equal(card.ID, cards.Card().ID)
+	assertTest.False(cards.Next())
+	assertTest.Nil(cards.Err())
+
+	builder := &transactions.TransactionBuilder{}
+	builder.Amount(transactions.NewMoney(20, 0)).PaymentMethod(transactions.CREDIT_CARD)
+	builder.CardID(card.ID)
+	builder.Name("Leandro")
+	builder.Document("251.854.650-26")
+	result, err := client.ExecuteWithCardHash(build(t, builder), transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.PAID, result.Status)
+	assertTest.Equal(card.ID, result.Card.ID)
+	assertTest.Equal("4444", result.CardLastDigits)
+
+	builder.Amount(transactions.NewMoney(20, 0)).PaymentMethod(transactions.CREDIT_CARD)
+	builder.CardID(card.ID)
+	builder.CardCVV("612")
+	refused, err := client.Execute(build(t, builder), transactions.BASIC_AUTH)
+	assertTest.Nil(err)
+	assertTest.Equal(transactions.REFUSED, refused.Status)
+
+	builder.Amount(transactions.NewMoney(20, 0)).PaymentMethod(transactions.CREDIT_CARD)
+	builder.CardID("card_unknown")
+	_, err = client.Execute(build(t, builder), transactions.BASIC_AUTH)
+	assertTest.NotNil(err)
+
+	_, err = client.CreateCard(1, "1_abc")
+	assertTest.NotNil(err)
+}
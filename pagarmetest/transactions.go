@@ -0,0 +1,650 @@
+package pagarmetest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"pagarme/postback"
+	"pagarme/transactions"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// transactionRequest mirrors the body sent by transactions.client.Execute.
+type transactionRequest struct {
+	Amount             int64                          `json:"amount"`
+	CardHash           string                         `json:"card_hash"`
+	CardHolderName     string                         `json:"card_holder_name"`
+	CardExpirationDate string                         `json:"card_expiration_date"`
+	CardNumber         string                         `json:"card_number"`
+	CardCVV            string                         `json:"card_cvv"`
+	CardID             string                         `json:"card_id"`
+	PaymentMethod      string                         `json:"payment_method"`
+	Capture            *bool                          `json:"capture"`
+	PostbackURL        string                         `json:"postback_url"`
+	Installments       int                            `json:"installments"`
+	BoletoExpiration   string                         `json:"boleto_expiration_date"`
+	Metadata           map[string]interface{}         `json:"metadata"`
+	Customer           customerRequest                `json:"customer"`
+	Billing            *transactions.Billing          `json:"billing"`
+	Shipping           *transactions.ShippingResponse `json:"shipping"`
+	Items              []transactions.ItemResponse    `json:"items"`
+	SplitRules         []splitRuleRequest             `json:"split_rules"`
+}
+
+type splitRuleRequest struct {
+	RecipientID         string `json:"recipient_id"`
+	Amount              int64  `json:"amount"`
+	Percentage          int    `json:"percentage"`
+	Liable              bool   `json:"liable"`
+	ChargeProcessingFee bool   `json:"charge_processing_fee"`
+	ChargeRemainderFee  bool   `json:"charge_remainder_fee"`
+}
+
+type customerRequest struct {
+	ExternalID   string            `json:"external_id"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	Country      string            `json:"country"`
+	Email        string            `json:"email"`
+	PhoneNumbers []string          `json:"phone_numbers"`
+	Birthday     transactions.Date `json:"birthday"`
+	Documents    []struct {
+		Type   string `json:"type"`
+		Number string `json:"number"`
+	} `json:"documents"`
+}
+
+type operationRequest struct {
+	Amount      int64                     `json:"amount"`
+	BankAccount *transactions.BankAccount `json:"bank_account"`
+}
+
+func (s *Server) createTransaction(w http.ResponseWriter, r *http.Request) {
+	request := transactionRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	if request.Amount <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "amount", "valor inválido")
+		return
+	}
+
+	if request.PaymentMethod == "" {
+		request.PaymentMethod = transactions.CREDIT_CARD.String()
+	}
+
+	if request.Installments == 0 {
+		request.Installments = 1
+	}
+
+	var boletoExpiration time.Time
+	if request.BoletoExpiration != "" {
+		var err error
+		if boletoExpiration, err = time.Parse(transactions.BOLETO_DATE_LAYOUT, request.BoletoExpiration); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", "boleto_expiration_date", "Data de vencimento inválida")
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := s.nextID
+	transaction := &transactions.TransactionResponse{
+		Object:        "transaction",
+		ID:            id,
+		Tid:           id,
+		Nsu:           id,
+		Status:        transactions.PROCESSING,
+		StatusReason:  "acquirer",
+		AcquirerName:  "pagarme",
+		DateCreated:   now,
+		DateUpdated:   now,
+		Amount:        request.Amount,
+		Installments:  request.Installments,
+		PaymentMethod: request.PaymentMethod,
+		CaptureMethod: "ecommerce",
+		PostbackURL:   request.PostbackURL,
+		Referer:       "api_key",
+		Billing:       request.Billing,
+		Shipping:      request.Shipping,
+		Items:         request.Items,
+		Metadata:      request.Metadata,
+	}
+
+	for i, rule := range request.SplitRules {
+		transaction.SplitRules = append(transaction.SplitRules, transactions.SplitRuleResponse{
+			Object:              "split_rule",
+			ID:                  fmt.Sprintf("sr_%v_%v", id, i+1),
+			RecipientID:         rule.RecipientID,
+			Amount:              rule.Amount,
+			Percentage:          rule.Percentage,
+			Liable:              rule.Liable,
+			ChargeProcessingFee: rule.ChargeProcessingFee,
+			ChargeRemainder:     rule.ChargeRemainderFee,
+		})
+	}
+
+	if transaction.Items == nil {
+		transaction.Items = []transactions.ItemResponse{}
+	}
+	if transaction.Metadata == nil {
+		transaction.Metadata = map[string]interface{}{}
+	}
+
+	var status transactions.Status
+	var card url.Values
+
+	switch request.PaymentMethod {
+	case transactions.BOLETO.String():
+		expiration := now.AddDate(0, 0, 7)
+		if !boletoExpiration.IsZero() {
+			expiration = boletoExpiration
+		}
+		transaction.BoletoURL = BOLETO_URL
+		transaction.BoletoBarcode = BOLETO_BARCODE
+		transaction.BoletoExpirationDate = &expiration
+		transaction.AuthorizedAmount = request.Amount
+		status = transactions.WAITING_PAYMENT
+
+	case transactions.CREDIT_CARD.String():
+		card = url.Values{
+			"card_number":          {request.CardNumber},
+			"card_holder_name":     {request.CardHolderName},
+			"card_expiration_date": {request.CardExpirationDate},
+			"card_cvv":             {request.CardCVV},
+		}
+
+		if request.CardHash != "" {
+			decrypted, ok := s.decryptCardHash(request.CardHash)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "invalid_parameter", "card_hash", "card_hash inválido")
+				return
+			}
+			card = decrypted
+		}
+
+		if request.CardID != "" {
+			stored, ok := s.cardValues(request.CardID, request.CardCVV)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "invalid_parameter", "card_id", "Cartão não encontrado")
+				return
+			}
+			card = stored
+		}
+
+		if card.Get("card_number") == "" {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", "card_number", "Número do cartão está faltando")
+			return
+		}
+
+		setCard(transaction, card, now)
+		if request.CardID != "" {
+			transaction.Card = s.cards[request.CardID].card
+		}
+		status = s.authorize(transaction, card, request.Capture)
+
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "payment_method", "Método de pagamento inválido")
+		return
+	}
+
+	transaction.Customer = s.newCustomer(request.Customer, now)
+	if transaction.Card != nil && request.CardID == "" {
+		s.storeCard(transaction.Card, card, transaction.Customer.ID)
+	}
+	s.transactions[transaction.ID] = transaction
+	s.order = append(s.order, transaction.ID)
+	s.postbackURLs[transaction.ID] = request.PostbackURL
+	s.setStatus(transaction, status)
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// authorize simulates the acquirer and antifraud answers, described in the
+// package documentation.
+func (s *Server) authorize(transaction *transactions.TransactionResponse, card url.Values, capture *bool) transactions.Status {
+	if card.Get("card_number") == REFUSED_CARD_NUMBER || strings.HasPrefix(card.Get("card_cvv"), "6") {
+		transaction.RefuseReason = "acquirer"
+		transaction.AcquirerResponseCode = "1011"
+		return transactions.REFUSED
+	}
+
+	if transaction.Amount%100 == ANTIFRAUD_REFUSED_CENTS {
+		transaction.RefuseReason = "antifraud"
+		transaction.StatusReason = "antifraud"
+		score := 95.0
+		transaction.AntifraudScore = &score
+		return transactions.REFUSED
+	}
+
+	transaction.AcquirerResponseCode = "0000"
+	transaction.AuthorizationCode = strconv.Itoa(100000 + transaction.ID)
+	transaction.AuthorizedAmount = transaction.Amount
+	transaction.Cost = 50
+
+	if capture != nil && !*capture {
+		return transactions.AUTHORIZED
+	}
+
+	transaction.PaidAmount = transaction.Amount
+	return transactions.PAID
+}
+
+func setCard(transaction *transactions.TransactionResponse, card url.Values, now time.Time) {
+	transaction.Card = newCard("", card, now)
+	transaction.Card.ID = fmt.Sprintf("card_%v%v", transaction.Card.FirstDigits, transaction.Card.LastDigits)
+	transaction.CardHolderName = transaction.Card.HolderName
+	transaction.CardFirstDigits = transaction.Card.FirstDigits
+	transaction.CardLastDigits = transaction.Card.LastDigits
+	transaction.CardBrand = transaction.Card.Brand
+}
+
+func newCard(id string, card url.Values, now time.Time) *transactions.Card {
+	number := card.Get("card_number")
+	firstDigits, lastDigits := number, number
+	if len(number) >= 10 {
+		firstDigits, lastDigits = number[:6], number[len(number)-4:]
+	}
+
+	brand := transactions.DetectCardBrand(number)
+
+	return &transactions.Card{
+		Object:         "card",
+		ID:             id,
+		DateCreated:    now,
+		DateUpdated:    now,
+		Brand:          brand,
+		HolderName:     card.Get("card_holder_name"),
+		FirstDigits:    firstDigits,
+		LastDigits:     lastDigits,
+		Country:        "BRAZIL",
+		Fingerprint:    fmt.Sprintf("%x", []byte(number)),
+		Valid:          true,
+		ExpirationDate: card.Get("card_expiration_date"),
+	}
+}
+
+// setStatus changes the transaction status and sends its postback. It must
+// be called with s.mu held.
+func (s *Server) setStatus(transaction *transactions.TransactionResponse, status transactions.Status) {
+	oldStatus := transaction.Status
+	transaction.Status = status
+	transaction.DateUpdated = time.Now().UTC()
+
+	postbackURL := s.postbackURLs[transaction.ID]
+	if postbackURL == "" || oldStatus == status {
+		return
+	}
+
+	body := postbackBody(*transaction, oldStatus).Encode()
+
+	s.postbacks.Add(1)
+	go func() {
+		defer s.postbacks.Done()
+
+		req, err := http.NewRequest(http.MethodPost, postbackURL, strings.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", "PagarMe-Hookshot/1.0")
+		req.Header.Set(postback.SIGNATURE_HEADER, postback.Sign([]byte(body), s.apiKey))
+
+		res, err := s.httpClient.Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+}
+
+func postbackBody(transaction transactions.TransactionResponse, oldStatus transactions.Status) url.Values {
+	id := strconv.Itoa(transaction.ID)
+	values := url.Values{}
+	values.Set("id", id)
+	values.Set("fingerprint", fmt.Sprintf("%x", transaction.DateUpdated.UnixNano()))
+	values.Set("event", postback.EVENT_TRANSACTION_STATUS_CHANGED)
+	values.Set("old_status", oldStatus.String())
+	values.Set("desired_status", transaction.Status.String())
+	values.Set("current_status", transaction.Status.String())
+	values.Set("object", "transaction")
+	values.Set("transaction[object]", "transaction")
+	values.Set("transaction[id]", id)
+	values.Set("transaction[status]", transaction.Status.String())
+	values.Set("transaction[amount]", strconv.FormatInt(transaction.Amount, 10))
+	values.Set("transaction[authorized_amount]", strconv.FormatInt(transaction.AuthorizedAmount, 10))
+	values.Set("transaction[paid_amount]", strconv.FormatInt(transaction.PaidAmount, 10))
+	values.Set("transaction[refunded_amount]", strconv.FormatInt(transaction.RefundedAmount, 10))
+	values.Set("transaction[installments]", strconv.Itoa(transaction.Installments))
+	values.Set("transaction[payment_method]", transaction.PaymentMethod)
+	values.Set("transaction[card_brand]", transaction.CardBrand.String())
+	values.Set("transaction[card_last_digits]", transaction.CardLastDigits)
+	values.Set("transaction[boleto_url]", transaction.BoletoURL)
+	values.Set("transaction[boleto_barcode]", transaction.BoletoBarcode)
+	values.Set("transaction[refuse_reason]", transaction.RefuseReason)
+
+	for key, value := range transaction.Metadata {
+		values.Set("transaction[metadata]["+key+"]", fmt.Sprint(value))
+	}
+
+	return values
+}
+
+func (s *Server) getTransaction(w http.ResponseWriter, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Transaction not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// listTransactions supports the filters sent by transactions.TransactionFilter,
+// returning the newest transactions first.
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	count, _ := strconv.Atoi(q.Get("count"))
+	if count <= 0 {
+		count = 10
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []*transactions.TransactionResponse{}
+	for i := len(s.order) - 1; i >= 0; i-- {
+		transaction := s.transactions[s.order[i]]
+		if matches(transaction, q) {
+			found = append(found, transaction)
+		}
+	}
+
+	start := (page - 1) * count
+	if start > len(found) {
+		start = len(found)
+	}
+	end := start + count
+	if end > len(found) {
+		end = len(found)
+	}
+
+	writeJSON(w, http.StatusOK, found[start:end])
+}
+
+func matches(transaction *transactions.TransactionResponse, q url.Values) bool {
+	if status := q.Get("status"); status != "" && status != transaction.Status.String() {
+		return false
+	}
+
+	if method := q.Get("payment_method"); method != "" && method != transaction.PaymentMethod {
+		return false
+	}
+
+	if document := q.Get("customer[document_number]"); document != "" {
+		found := false
+		for _, doc := range transaction.Customer.Documents {
+			found = found || doc.Number == document
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, condition := range q["date_created"] {
+		milliseconds, _ := strconv.ParseInt(strings.TrimLeft(condition, "<>="), 10, 64)
+		limit := time.Unix(0, milliseconds*int64(time.Millisecond))
+		if strings.HasPrefix(condition, ">=") && transaction.DateCreated.Before(limit) {
+			return false
+		}
+		if strings.HasPrefix(condition, "<=") && transaction.DateCreated.After(limit) {
+			return false
+		}
+	}
+
+	for key, values := range q {
+		if strings.HasPrefix(key, "metadata[") && strings.HasSuffix(key, "]") {
+			name := strings.TrimSuffix(strings.TrimPrefix(key, "metadata["), "]")
+			if fmt.Sprint(transaction.Metadata[name]) != values[0] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *Server) capture(w http.ResponseWriter, r *http.Request, id int) {
+	request := operationRequest{}
+	decodeBody(r, &request)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Transaction not found")
+		return
+	}
+
+	if transaction.Status != transactions.AUTHORIZED {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "status", fmt.Sprintf("Transação com status %v não pode ser capturada", transaction.Status))
+		return
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		amount = transaction.AuthorizedAmount
+	}
+
+	if amount > transaction.AuthorizedAmount {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "amount", "Valor acima do autorizado")
+		return
+	}
+
+	transaction.PaidAmount = amount
+	s.setStatus(transaction, transactions.PAID)
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+func (s *Server) refund(w http.ResponseWriter, r *http.Request, id int) {
+	request := operationRequest{}
+	decodeBody(r, &request)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Transaction not found")
+		return
+	}
+
+	if transaction.Status == transactions.AUTHORIZED {
+		s.setStatus(transaction, transactions.REFUNDED)
+		writeJSON(w, http.StatusOK, transaction)
+		return
+	}
+
+	if transaction.Status != transactions.PAID {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "status", fmt.Sprintf("Transação com status %v não pode ser estornada", transaction.Status))
+		return
+	}
+
+	available := transaction.PaidAmount - transaction.RefundedAmount
+	amount := request.Amount
+	if amount == 0 {
+		amount = available
+	}
+
+	if amount > available {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "amount", "Valor acima do disponível para estorno")
+		return
+	}
+
+	if transaction.PaymentMethod == transactions.BOLETO.String() {
+		if request.BankAccount == nil {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", "bank_account", "Dados bancários estão faltando")
+			return
+		}
+		transaction.RefundedAmount += amount
+		s.setStatus(transaction, transactions.PENDING_REFUND)
+		writeJSON(w, http.StatusOK, transaction)
+		return
+	}
+
+	transaction.RefundedAmount += amount
+	if transaction.RefundedAmount == transaction.PaidAmount {
+		s.setStatus(transaction, transactions.REFUNDED)
+	}
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// newCustomer stores the customer sent with a transaction. It must be
+// called with s.mu held.
+func (s *Server) newCustomer(request customerRequest, now time.Time) *transactions.CustomerResponse {
+	s.nextID++
+	customer := &transactions.CustomerResponse{
+		Object:       "customer",
+		ID:           s.nextID,
+		ExternalID:   request.ExternalID,
+		Type:         request.Type,
+		Country:      request.Country,
+		Name:         request.Name,
+		Email:        request.Email,
+		PhoneNumbers: request.PhoneNumbers,
+		Birthday:     request.Birthday,
+		DateCreated:  now,
+		Documents:    []transactions.DocumentResponse{},
+	}
+
+	for i, document := range request.Documents {
+		customer.Documents = append(customer.Documents, transactions.DocumentResponse{
+			Object: "document",
+			ID:     fmt.Sprintf("doc_%v_%v", customer.ID, i),
+			Type:   document.Type,
+			Number: document.Number,
+		})
+		customer.DocumentType = document.Type
+	}
+
+	s.customers[customer.ID] = customer
+	return customer
+}
+
+func (s *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	request := customerRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "", "JSON inválido")
+		return
+	}
+
+	if strings.TrimSpace(request.Name) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "name", "Nome está faltando")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.newCustomer(request, time.Now().UTC()))
+}
+
+func (s *Server) getCustomer(w http.ResponseWriter, id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "", "Customer not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customer)
+}
+
+func (s *Server) listCustomers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, 0, len(s.customers))
+	for id := range s.customers {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	customers := make([]*transactions.CustomerResponse, 0, len(ids))
+	for _, id := range ids {
+		customers = append(customers, s.customers[id])
+	}
+
+	start, end := pageBounds(r.URL.Query(), len(customers))
+	writeJSON(w, http.StatusOK, customers[start:end])
+}
+
+// calculateInstallments answers with the installment table indexed by the
+// number of installments. The installments after the free ones add the
+// interest rate once per installment, rounded half up to the centavo.
+func (s *Server) calculateInstallments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	amount, _ := strconv.ParseInt(q.Get("amount"), 10, 64)
+	maxInstallments, _ := strconv.ParseInt(q.Get("max_installments"), 10, 64)
+	freeInstallments, _ := strconv.ParseInt(q.Get("free_installments"), 10, 64)
+
+	rate, err := 0.0, error(nil)
+	if value := q.Get("interest_rate"); value != "" {
+		rate, err = strconv.ParseFloat(value, 64)
+	}
+
+	switch {
+	case amount <= 0:
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "amount", "valor inválido")
+		return
+	case maxInstallments < 1 || maxInstallments > transactions.MAX_INSTALLMENTS:
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "max_installments", "Número de parcelas inválido")
+		return
+	case freeInstallments < 0 || freeInstallments > maxInstallments:
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "free_installments", "Número de parcelas sem juros inválido")
+		return
+	case err != nil || rate < 0 || rate > 100:
+		writeError(w, http.StatusBadRequest, "invalid_parameter", "interest_rate", "Taxa de juros inválida")
+		return
+	}
+
+	hundredths := int64(math.Round(rate * 100))
+	table := map[string]transactions.Installment{}
+	for n := int64(1); n <= maxInstallments; n++ {
+		total := amount
+		if n > freeInstallments {
+			total += (amount*hundredths*n + 5000) / 10000
+		}
+
+		table[strconv.FormatInt(n, 10)] = transactions.Installment{
+			Installment:       int(n),
+			Amount:            transactions.Money(total),
+			InstallmentAmount: transactions.Money((total + n/2) / n),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"installments": table})
+}
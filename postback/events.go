@@ -0,0 +1,191 @@
+package postback
+
+import (
+	"net/url"
+	"pagarme/transactions"
+	"strconv"
+	"strings"
+)
+
+const (
+	EVENT_TRANSACTION_STATUS_CHANGED  = "transaction_status_changed"
+	EVENT_SUBSCRIPTION_STATUS_CHANGED = "subscription_status_changed"
+	EVENT_RECIPIENT_STATUS_CHANGED    = "recipient_status_changed"
+)
+
+// Event holds the fields common to every postback. Fields keeps the whole
+// form body for values not mapped by the typed events.
+type Event struct {
+	ID            string
+	Event         string
+	Object        string
+	Fingerprint   string
+	OldStatus     string
+	CurrentStatus string
+	DesiredStatus string
+	Fields        url.Values
+}
+
+type TransactionEvent struct {
+	Event
+	Transaction Transaction
+}
+
+type Transaction struct {
+	ID               int
+	Status           transactions.Status
+	Amount           int64
+	AuthorizedAmount int64
+	PaidAmount       int64
+	RefundedAmount   int64
+	Installments     int
+	PaymentMethod    string
+	CardBrand        transactions.CardBrand
+	CardLastDigits   string
+	BoletoURL        string
+	BoletoBarcode    string
+	RefuseReason     string
+	SubscriptionID   int
+	Metadata         map[string]string
+}
+
+type SubscriptionEvent struct {
+	Event
+	Subscription Subscription
+}
+
+type Subscription struct {
+	ID                   int
+	Status               string
+	PlanID               int
+	CurrentTransactionID int
+	PaymentMethod        string
+	Metadata             map[string]string
+}
+
+type RecipientEvent struct {
+	Event
+	Recipient Recipient
+}
+
+type Recipient struct {
+	ID                            string
+	Status                        string
+	StatusReason                  string
+	TransferEnabled               bool
+	TransferInterval              string
+	AnticipatableVolumePercentage int
+}
+
+func newEvent(fields url.Values) Event {
+	return Event{
+		ID:            fields.Get("id"),
+		Event:         fields.Get("event"),
+		Object:        fields.Get("object"),
+		Fingerprint:   fields.Get("fingerprint"),
+		OldStatus:     fields.Get("old_status"),
+		CurrentStatus: fields.Get("current_status"),
+		DesiredStatus: fields.Get("desired_status"),
+		Fields:        fields,
+	}
+}
+
+func newTransactionEvent(event Event) TransactionEvent {
+	f := object(event.Fields, "transaction")
+
+	return TransactionEvent{
+		Event: event,
+		Transaction: Transaction{
+			ID:               f.int("id"),
+			Status:           transactions.Status(f.get("status")),
+			Amount:           f.int64("amount"),
+			AuthorizedAmount: f.int64("authorized_amount"),
+			PaidAmount:       f.int64("paid_amount"),
+			RefundedAmount:   f.int64("refunded_amount"),
+			Installments:     f.int("installments"),
+			PaymentMethod:    f.get("payment_method"),
+			CardBrand:        transactions.CardBrand(f.get("card_brand")),
+			CardLastDigits:   f.get("card_last_digits"),
+			BoletoURL:        f.get("boleto_url"),
+			BoletoBarcode:    f.get("boleto_barcode"),
+			RefuseReason:     f.get("refuse_reason"),
+			SubscriptionID:   f.int("subscription_id"),
+			Metadata:         f.object("metadata"),
+		},
+	}
+}
+
+func newSubscriptionEvent(event Event) SubscriptionEvent {
+	f := object(event.Fields, "subscription")
+
+	return SubscriptionEvent{
+		Event: event,
+		Subscription: Subscription{
+			ID:                   f.int("id"),
+			Status:               f.get("status"),
+			PlanID:               f.int("plan.id"),
+			CurrentTransactionID: f.int("current_transaction.id"),
+			PaymentMethod:        f.get("payment_method"),
+			Metadata:             f.object("metadata"),
+		},
+	}
+}
+
+func newRecipientEvent(event Event) RecipientEvent {
+	f := object(event.Fields, "recipient")
+
+	return RecipientEvent{
+		Event: event,
+		Recipient: Recipient{
+			ID:                            f.get("id"),
+			Status:                        f.get("status"),
+			StatusReason:                  f.get("status_reason"),
+			TransferEnabled:               f.get("transfer_enabled") == "true",
+			TransferInterval:              f.get("transfer_interval"),
+			AnticipatableVolumePercentage: f.int("anticipatable_volume_percentage"),
+		},
+	}
+}
+
+// fields reads the bracket notation keys of a form body below a prefix:
+// object(values, "subscription").get("plan.id") reads "subscription[plan][id]".
+type fields struct {
+	values url.Values
+	prefix string
+}
+
+func object(values url.Values, name string) fields {
+	return fields{values, name}
+}
+
+func (f fields) get(key string) string {
+	name := f.prefix
+	for _, part := range strings.Split(key, ".") {
+		name += "[" + part + "]"
+	}
+	return f.values.Get(name)
+}
+
+func (f fields) int(key string) int {
+	value, _ := strconv.Atoi(f.get(key))
+	return value
+}
+
+func (f fields) int64(key string) int64 {
+	value, _ := strconv.ParseInt(f.get(key), 10, 64)
+	return value
+}
+
+// object collects the keys nested below key, such as every metadata entry.
+func (f fields) object(key string) map[string]string {
+	prefix := f.prefix + "[" + key + "]["
+	result := map[string]string{}
+
+	for name, values := range f.values {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, "]") && len(values) > 0 {
+			result[strings.TrimSuffix(strings.TrimPrefix(name, prefix), "]")] = values[0]
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,153 @@
+package postback
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"pagarme/transactions"
+	"strings"
+)
+
+const SIGNATURE_HEADER = "X-Hub-Signature"
+const MAX_BODY_SIZE = 1 << 20
+
+// Sign returns the X-Hub-Signature value Pagar.me sends with body: the
+// HMAC-SHA1 of the raw body keyed by the api_key.
+func Sign(body []byte, apiKey string) string {
+	mac := hmac.New(sha1.New, []byte(apiKey))
+	mac.Write(body)
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the X-Hub-Signature of body.
+func Verify(body []byte, signature string, apiKey string) bool {
+	return hmac.Equal([]byte(Sign(body, apiKey)), []byte(strings.TrimSpace(signature)))
+}
+
+// Handler receives Pagar.me postbacks. Requests with an invalid signature
+// are answered with 401. When a callback returns an error the postback is
+// answered with 500, so Pagar.me sends it again later.
+//
+//	handler := postback.NewHandler(apiKey)
+//	handler.OnTransaction(func(event postback.TransactionEvent) error {
+//		return orders.Update(event.Transaction.ID, event.Transaction.Status)
+//	})
+//	http.Handle("/postback", handler)
+type Handler struct {
+	apiKey         string
+	onTransaction  []func(TransactionEvent) error
+	onSubscription []func(SubscriptionEvent) error
+	onRecipient    []func(RecipientEvent) error
+	onOther        []func(Event) error
+	logger         transactions.Logger
+}
+
+func NewHandler(apiKey string) *Handler {
+	return &Handler{apiKey: apiKey, logger: transactions.NopLogger()}
+}
+
+// SetLogger sends the handler logs to logger, such as a *slog.Logger.
+// Nothing is logged by default.
+func (h *Handler) SetLogger(logger transactions.Logger) {
+	if logger == nil {
+		logger = transactions.NopLogger()
+	}
+	h.logger = logger
+}
+
+func (h *Handler) OnTransaction(callback func(TransactionEvent) error) {
+	h.onTransaction = append(h.onTransaction, callback)
+}
+
+func (h *Handler) OnSubscription(callback func(SubscriptionEvent) error) {
+	h.onSubscription = append(h.onSubscription, callback)
+}
+
+func (h *Handler) OnRecipient(callback func(RecipientEvent) error) {
+	h.onRecipient = append(h.onRecipient, callback)
+}
+
+// OnOther registers a callback for events without a typed callback.
+func (h *Handler) OnOther(callback func(Event) error) {
+	h.onOther = append(h.onOther, callback)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MAX_BODY_SIZE))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !Verify(body, r.Header.Get(SIGNATURE_HEADER), h.apiKey) {
+		h.logger.Warn("Postback with invalid signature", "remote_addr", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	event, err := Parse(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(event); err != nil {
+		h.logger.Error("Postback callback error", "id", event.ID, "error", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Parse decodes a form-encoded postback body.
+func Parse(body []byte) (Event, error) {
+	fields, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Event{}, err
+	}
+
+	return newEvent(fields), nil
+}
+
+func (h *Handler) dispatch(event Event) error {
+	switch {
+	case event.Event == EVENT_TRANSACTION_STATUS_CHANGED && len(h.onTransaction) > 0:
+		transactionEvent := newTransactionEvent(event)
+		for _, callback := range h.onTransaction {
+			if err := callback(transactionEvent); err != nil {
+				return err
+			}
+		}
+	case event.Event == EVENT_SUBSCRIPTION_STATUS_CHANGED && len(h.onSubscription) > 0:
+		subscriptionEvent := newSubscriptionEvent(event)
+		for _, callback := range h.onSubscription {
+			if err := callback(subscriptionEvent); err != nil {
+				return err
+			}
+		}
+	case event.Event == EVENT_RECIPIENT_STATUS_CHANGED && len(h.onRecipient) > 0:
+		recipientEvent := newRecipientEvent(event)
+		for _, callback := range h.onRecipient {
+			if err := callback(recipientEvent); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, callback := range h.onOther {
+			if err := callback(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package postback
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pagarme/transactions"
+	"strings"
+	"testing"
+)
+
+const apiKey = "ak_test_key"
+
+func transactionBody() string {
+	values := url.Values{}
+	values.Set("id", "1234")
+	values.Set("fingerprint", "f7a1c6a2")
+	values.Set("event", EVENT_TRANSACTION_STATUS_CHANGED)
+	values.Set("old_status", "waiting_payment")
+	values.Set("desired_status", "paid")
+	values.Set("current_status", "paid")
+	values.Set("object", "transaction")
+	values.Set("transaction[object]", "transaction")
+	values.Set("transaction[id]", "1234")
+	values.Set("transaction[status]", "paid")
+	values.Set("transaction[amount]", "3300")
+	values.Set("transaction[paid_amount]", "3300")
+	values.Set("transaction[payment_method]", "boleto")
+	values.Set("transaction[boleto_url]", "https://pagar.me")
+	values.Set("transaction[metadata][idempotency_key]", "key")
+	values.Set("transaction[metadata][pedido]", "42")
+	return values.Encode()
+}
+
+func post(handler http.Handler, body string, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/postback", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(SIGNATURE_HEADER, signature)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSign(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("sha1=de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9", Sign([]byte("The quick brown fox jumps over the lazy dog"), "key"))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(transactionBody())
+
+	assertTest := assert.New(t)
+	assertTest.True(Verify(body, Sign(body, apiKey), apiKey))
+	assertTest.False(Verify(body, Sign(body, "other"), apiKey))
+	assertTest.False(Verify(body, "", apiKey))
+}
+
+func TestHandlerTransaction(t *testing.T) {
+	var received []TransactionEvent
+	handler := NewHandler(apiKey)
+	handler.OnTransaction(func(event TransactionEvent) error {
+		received = append(received, event)
+		return nil
+	})
+
+	body := transactionBody()
+	rec := post(handler, body, Sign([]byte(body), apiKey))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Len(received, 1)
+
+	event := received[0]
+	assertTest.Equal("1234", event.ID)
+	assertTest.Equal("waiting_payment", event.OldStatus)
+	assertTest.Equal("paid", event.CurrentStatus)
+	assertTest.Equal(1234, event.Transaction.ID)
+	assertTest.Equal(transactions.PAID, event.Transaction.Status)
+	assertTest.Equal(int64(3300), event.Transaction.PaidAmount)
+	assertTest.Equal("https://pagar.me", event.Transaction.BoletoURL)
+	assertTest.Equal(map[string]string{"idempotency_key": "key", "pedido": "42"}, event.Transaction.Metadata)
+}
+
+func TestHandlerInvalidSignature(t *testing.T) {
+	called := false
+	handler := NewHandler(apiKey)
+	handler.OnTransaction(func(event TransactionEvent) error {
+		called = true
+		return nil
+	})
+
+	body := transactionBody()
+	rec := post(handler, body, Sign([]byte(body), "ak_test_other"))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusUnauthorized, rec.Code)
+	assertTest.False(called)
+}
+
+func TestHandlerCallbackError(t *testing.T) {
+	handler := NewHandler(apiKey)
+	handler.OnTransaction(func(event TransactionEvent) error {
+		return errors.New("database unavailable")
+	})
+
+	body := transactionBody()
+	rec := post(handler, body, Sign([]byte(body), apiKey))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusInternalServerError, rec.Code)
+}
+
+func TestHandlerSubscription(t *testing.T) {
+	values := url.Values{}
+	values.Set("id", "99")
+	values.Set("event", EVENT_SUBSCRIPTION_STATUS_CHANGED)
+	values.Set("object", "subscription")
+	values.Set("old_status", "paid")
+	values.Set("current_status", "unpaid")
+	values.Set("subscription[id]", "99")
+	values.Set("subscription[status]", "unpaid")
+	values.Set("subscription[plan][id]", "7")
+	values.Set("subscription[current_transaction][id]", "1234")
+	body := values.Encode()
+
+	var received SubscriptionEvent
+	handler := NewHandler(apiKey)
+	handler.OnSubscription(func(event SubscriptionEvent) error {
+		received = event
+		return nil
+	})
+
+	rec := post(handler, body, Sign([]byte(body), apiKey))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Equal(99, received.Subscription.ID)
+	assertTest.Equal("unpaid", received.Subscription.Status)
+	assertTest.Equal(7, received.Subscription.PlanID)
+	assertTest.Equal(1234, received.Subscription.CurrentTransactionID)
+}
+
+func TestHandlerRecipient(t *testing.T) {
+	values := url.Values{}
+	values.Set("id", "re_ci7nheu0m0006n016o5sglg9t")
+	values.Set("event", EVENT_RECIPIENT_STATUS_CHANGED)
+	values.Set("object", "recipient")
+	values.Set("old_status", "registration")
+	values.Set("current_status", "active")
+	values.Set("recipient[id]", "re_ci7nheu0m0006n016o5sglg9t")
+	values.Set("recipient[status]", "active")
+	values.Set("recipient[transfer_enabled]", "true")
+	body := values.Encode()
+
+	var received RecipientEvent
+	handler := NewHandler(apiKey)
+	handler.OnRecipient(func(event RecipientEvent) error {
+		received = event
+		return nil
+	})
+
+	rec := post(handler, body, Sign([]byte(body), apiKey))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Equal("re_ci7nheu0m0006n016o5sglg9t", received.Recipient.ID)
+	assertTest.Equal("active", received.Recipient.Status)
+	assertTest.True(received.Recipient.TransferEnabled)
+}
+
+func TestHandlerOther(t *testing.T) {
+	values := url.Values{}
+	values.Set("id", "1")
+	values.Set("event", "transaction_created")
+	body := values.Encode()
+
+	var received Event
+	handler := NewHandler(apiKey)
+	handler.OnOther(func(event Event) error {
+		received = event
+		return nil
+	})
+
+	rec := post(handler, body, Sign([]byte(body), apiKey))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusOK, rec.Code)
+	assertTest.Equal("transaction_created", received.Event)
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(apiKey).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/postback", nil))
+
+	assertTest := assert.New(t)
+	assertTest.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,237 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"pagarme/documents"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const PATH_BANK_ACCOUNTS = "/bank_accounts"
+const PATH_BANK_ACCOUNT_ID = "/bank_accounts/%v"
+const MAX_LEGAL_NAME = 30
+
+// BankAccountType is the kind of a bank account.
+type BankAccountType string
+
+const (
+	CONTA_CORRENTE          BankAccountType = "conta_corrente"
+	CONTA_POUPANCA          BankAccountType = "conta_poupanca"
+	CONTA_CORRENTE_CONJUNTA BankAccountType = "conta_corrente_conjunta"
+	CONTA_POUPANCA_CONJUNTA BankAccountType = "conta_poupanca_conjunta"
+)
+
+var bankCodeRegex = regexp.MustCompile(`^\d{3}$`)
+var agenciaRegex = regexp.MustCompile(`^\d{1,4}$`)
+var agenciaDvRegex = regexp.MustCompile(`^[0-9A-Za-z]$`)
+var contaRegex = regexp.MustCompile(`^\d{1,13}$`)
+var contaDvRegex = regexp.MustCompile(`^[0-9A-Za-z]{1,2}$`)
+
+// checkDigits are the rules of the banks whose check digits are known: the
+// accepted digits of an agencia and of an agencia and conta.
+var checkDigits = map[string]struct {
+	agencia func(agencia string) []string
+	conta   func(agencia string, conta string) []string
+}{
+	// Banco do Brasil
+	"001": {
+		agencia: func(agencia string) []string { return mod11Digit(agencia, 9, "X") },
+		conta:   func(agencia string, conta string) []string { return mod11Digit(conta, 9, "X") },
+	},
+	// Bradesco, which writes 10 as P or, in some systems, as 0
+	"237": {
+		agencia: func(agencia string) []string { return mod11Digit(agencia, 9, "P", "0") },
+		conta:   func(agencia string, conta string) []string { return mod11Digit(conta, 7, "P", "0") },
+	},
+	// Itaú, whose conta digit also depends on the agencia
+	"341": {
+		conta: func(agencia string, conta string) []string {
+			if len(agencia) > 4 || len(conta) > 5 {
+				return nil
+			}
+			return []string{mod10Digit(leftPad(agencia, 4) + leftPad(conta, 5))}
+		},
+	},
+}
+
+// ValidateBankAccount checks the bank code, the agencia, the conta and, for
+// Banco do Brasil, Bradesco and Itaú, their check digits, as well as the
+// document and the legal name of the owner. It returns the account with the
+// document as digits and the check digits in upper case. The bank code is
+// only checked to be 3 digits other than 000, not against the list of banks,
+// which is left to Pagar.me.
+func ValidateBankAccount(account BankAccount) (BankAccount, error) {
+	switch {
+	case !bankCodeRegex.MatchString(account.BankCode) || account.BankCode == "000":
+		return account, &InvalidValueError{"BankAccount.BankCode", account.BankCode}
+	case !agenciaRegex.MatchString(account.Agencia):
+		return account, &InvalidValueError{"BankAccount.Agencia", account.Agencia}
+	case account.AgenciaDv != "" && !agenciaDvRegex.MatchString(account.AgenciaDv):
+		return account, &InvalidValueError{"BankAccount.AgenciaDv", account.AgenciaDv}
+	case !contaRegex.MatchString(account.Conta):
+		return account, &InvalidValueError{"BankAccount.Conta", account.Conta}
+	case !contaDvRegex.MatchString(account.ContaDv):
+		return account, &InvalidValueError{"BankAccount.ContaDv", account.ContaDv}
+	}
+
+	switch BankAccountType(account.Type) {
+	case "", CONTA_CORRENTE, CONTA_POUPANCA, CONTA_CORRENTE_CONJUNTA, CONTA_POUPANCA_CONJUNTA:
+	default:
+		return account, &InvalidValueError{"BankAccount.Type", account.Type}
+	}
+
+	account.AgenciaDv = strings.ToUpper(account.AgenciaDv)
+	account.ContaDv = strings.ToUpper(account.ContaDv)
+
+	if rules, ok := checkDigits[account.BankCode]; ok {
+		if rules.agencia != nil && account.AgenciaDv != "" && !contains(rules.agencia(account.Agencia), account.AgenciaDv) {
+			return account, &InvalidValueError{"BankAccount.AgenciaDv", account.AgenciaDv}
+		}
+		if rules.conta != nil && !contains(rules.conta(account.Agencia, account.Conta), account.ContaDv) {
+			return account, &InvalidValueError{"BankAccount.ContaDv", account.ContaDv}
+		}
+	}
+
+	_, number, err := documents.Validate(account.DocumentNumber)
+	if err != nil {
+		return account, &InvalidValueError{"BankAccount.DocumentNumber", account.DocumentNumber}
+	}
+	account.DocumentNumber = number
+
+	if strings.TrimSpace(account.LegalName) == "" || utf8.RuneCountInString(account.LegalName) > MAX_LEGAL_NAME {
+		return account, &InvalidValueError{"BankAccount.LegalName", account.LegalName}
+	}
+
+	return account, nil
+}
+
+// mod11Digit returns the check digit of value by modulo 11, with the weights
+// 2 to maxWeight repeated from the right. A result of 10 is written as any
+// of ten and a result of 11 as 0.
+func mod11Digit(value string, maxWeight int, ten ...string) []string {
+	sum, weight := 0, 2
+	for i := len(value) - 1; i >= 0; i-- {
+		sum += int(value[i]-'0') * weight
+		if weight++; weight > maxWeight {
+			weight = 2
+		}
+	}
+
+	switch digit := 11 - sum%11; digit {
+	case 10:
+		return ten
+	case 11:
+		return []string{"0"}
+	default:
+		return []string{fmt.Sprint(digit)}
+	}
+}
+
+// mod10Digit returns the check digit of value by modulo 10, with the weights
+// 2 and 1 alternated from the right and the digits of each product summed.
+func mod10Digit(value string) string {
+	sum, weight := 0, 2
+	for i := len(value) - 1; i >= 0; i-- {
+		product := int(value[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+	return fmt.Sprint((10 - sum%10) % 10)
+}
+
+func leftPad(value string, length int) string {
+	if len(value) >= length {
+		return value
+	}
+	return strings.Repeat("0", length-len(value)) + value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDocument reports whether two documents, formatted or not, are the same.
+func sameDocument(a string, b string) bool {
+	return documents.Normalize(a) == documents.Normalize(b)
+}
+
+// CreateBankAccount registers account, validated by ValidateBankAccount, to
+// be used by recipients.
+func (c *client) CreateBankAccount(account BankAccount) (*BankAccount, error) {
+	return c.CreateBankAccountContext(context.Background(), account)
+}
+
+func (c *client) CreateBankAccountContext(ctx context.Context, account BankAccount) (*BankAccount, error) {
+	account, err := ValidateBankAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	result := BankAccount{}
+	if err := c.request(ctx, "POST", PATH_BANK_ACCOUNTS, account, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *client) GetBankAccount(id int) (*BankAccount, error) {
+	return c.GetBankAccountContext(context.Background(), id)
+}
+
+func (c *client) GetBankAccountContext(ctx context.Context, id int) (*BankAccount, error) {
+	result := BankAccount{}
+
+	if err := c.request(ctx, "GET", fmt.Sprintf(PATH_BANK_ACCOUNT_ID, id), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// BankAccountIterator walks the result of ListBankAccounts like
+// TransactionIterator.
+type BankAccountIterator struct {
+	pager
+	buffer  []BankAccount
+	current BankAccount
+}
+
+func (it *BankAccountIterator) Next() bool {
+	if len(it.buffer) == 0 && !it.next(&it.buffer) {
+		return false
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+func (it *BankAccountIterator) BankAccount() BankAccount {
+	return it.current
+}
+
+func (it *BankAccountIterator) Err() error {
+	return it.err
+}
+
+// ListBankAccounts returns an iterator over the bank accounts, only those of
+// documentNumber when it is not empty.
+func (c *client) ListBankAccounts(documentNumber string) *BankAccountIterator {
+	return c.ListBankAccountsContext(context.Background(), documentNumber)
+}
+
+func (c *client) ListBankAccountsContext(ctx context.Context, documentNumber string) *BankAccountIterator {
+	q := url.Values{}
+	if documentNumber != "" {
+		q.Add("document_number", documents.Normalize(documentNumber))
+	}
+	return &BankAccountIterator{pager: pager{ctx: ctx, client: c, path: PATH_BANK_ACCOUNTS, query: q}}
+}
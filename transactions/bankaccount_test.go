@@ -0,0 +1,142 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func bankAccount() BankAccount {
+	return BankAccount{
+		BankCode:       "001",
+		Agencia:        "1234",
+		AgenciaDv:      "3",
+		Conta:          "12345678",
+		ContaDv:        "9",
+		Type:           string(CONTA_CORRENTE),
+		DocumentNumber: "251.854.650-26",
+		LegalName:      "Leandro Greijal",
+	}
+}
+
+func TestValidateBankAccount(t *testing.T) {
+	account, err := ValidateBankAccount(bankAccount())
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("25185465026", account.DocumentNumber)
+}
+
+func TestValidateBankAccountCheckDigits(t *testing.T) {
+	tests := []struct {
+		bankCode  string
+		agencia   string
+		agenciaDv string
+		conta     string
+		contaDv   string
+		valid     bool
+	}{
+		{"001", "0001", "9", "555", "x", true},
+		{"001", "0001", "8", "555", "X", false},
+		{"001", "1234", "", "123456", "0", true},
+		{"001", "1234", "", "123456", "1", false},
+		{"237", "1234", "3", "0238069", "2", true},
+		{"237", "1234", "", "6", "P", true},
+		{"237", "1234", "", "6", "0", true},
+		{"237", "1234", "", "0238069", "3", false},
+		{"341", "0057", "", "72192", "0", true},
+		{"341", "1234", "", "12345", "1", true},
+		{"341", "1234", "", "12345", "2", false},
+		{"341", "1234", "", "123456", "1", false},
+		{"104", "1234", "", "12345678", "0", true},
+	}
+
+	for _, test := range tests {
+		account := bankAccount()
+		account.BankCode = test.bankCode
+		account.Agencia = test.agencia
+		account.AgenciaDv = test.agenciaDv
+		account.Conta = test.conta
+		account.ContaDv = test.contaDv
+
+		_, err := ValidateBankAccount(account)
+		assert.New(t).Equal(test.valid, err == nil, test.bankCode+" "+test.agencia+"-"+test.agenciaDv+" "+test.conta+"-"+test.contaDv)
+	}
+}
+
+func TestValidateBankAccountInvalid(t *testing.T) {
+	tests := []struct {
+		change func(*BankAccount)
+		err    string
+	}{
+		{func(a *BankAccount) { a.BankCode = "1" }, "BankAccount.BankCode is invalid. Value: 1"},
+		{func(a *BankAccount) { a.BankCode = "000" }, "BankAccount.BankCode is invalid. Value: 000"},
+		{func(a *BankAccount) { a.Agencia = "12345" }, "BankAccount.Agencia is invalid. Value: 12345"},
+		{func(a *BankAccount) { a.AgenciaDv = "12" }, "BankAccount.AgenciaDv is invalid. Value: 12"},
+		{func(a *BankAccount) { a.Conta = "12a" }, "BankAccount.Conta is invalid. Value: 12a"},
+		{func(a *BankAccount) { a.ContaDv = "" }, "BankAccount.ContaDv is invalid. Value: "},
+		{func(a *BankAccount) { a.Type = "conta_salario" }, "BankAccount.Type is invalid. Value: conta_salario"},
+		{func(a *BankAccount) { a.DocumentNumber = "111.111.111-11" }, "BankAccount.DocumentNumber is invalid. Value: 111.111.111-11"},
+		{func(a *BankAccount) { a.LegalName = "" }, "BankAccount.LegalName is invalid. Value: "},
+		{func(a *BankAccount) { a.LegalName = "Leandro Greijal da Silva Santos Junior" }, "BankAccount.LegalName is invalid. Value: Leandro Greijal da Silva Santos Junior"},
+	}
+
+	for _, test := range tests {
+		account := bankAccount()
+		test.change(&account)
+		_, err := ValidateBankAccount(account)
+		assert.New(t).EqualError(err, test.err)
+	}
+}
+
+func TestCreateBankAccount(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "bank_account", "id": 17, "bank_code": "001", "document_number": "25185465026"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.CreateBankAccount(bankAccount())
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(17, result.ID)
+	assertTest.Equal(http.MethodPost, requests[0].Method)
+	assertTest.Equal("/bank_accounts", requests[0].URL.Path)
+	assertTest.Equal("25185465026", bodies[0]["document_number"])
+	assertTest.Equal("12345678", bodies[0]["conta"])
+	assertTest.Nil(bodies[0]["id"])
+}
+
+func TestCreateBankAccountInvalid(t *testing.T) {
+	account := bankAccount()
+	account.ContaDv = "1"
+
+	result, err := NewClient(WithBaseURL("http://127.0.0.1:0")).CreateBankAccount(account)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "BankAccount.ContaDv is invalid. Value: 1")
+}
+
+func TestListBankAccounts(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `[{"id": 17}, {"id": 18}]`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListBankAccounts("251.854.650-26")
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.BankAccount().ID)
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal([]int{17, 18}, ids)
+	assertTest.Len(requests, 1)
+	assertTest.Equal("25185465026", requests[0].URL.Query().Get("document_number"))
+	assertTest.Equal("1", requests[0].URL.Query().Get("page"))
+}
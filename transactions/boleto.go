@@ -0,0 +1,134 @@
+package transactions
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BOLETO_DATE_LAYOUT is the format of boleto_expiration_date.
+const BOLETO_DATE_LAYOUT = "2006-01-02"
+const MAX_BOLETO_INSTRUCTIONS = 255
+
+// BoletoRule changes how a boleto is handled after its expiration date.
+type BoletoRule string
+
+const (
+	// STRICT_EXPIRATION_DATE refuses payments after the expiration date.
+	STRICT_EXPIRATION_DATE BoletoRule = "strict_expiration_date"
+	// NO_STRICT accepts payments after the expiration date.
+	NO_STRICT BoletoRule = "no_strict"
+)
+
+// BoletoCharge is the fine or the interest added to a boleto paid Days
+// after its expiration date. It is either a fixed Amount or a Percentage of
+// the boleto amount, never both.
+type BoletoCharge struct {
+	Days       int
+	Amount     Money
+	Percentage InterestRate
+}
+
+// boletoCharge is the request format of BoletoCharge.
+type boletoCharge struct {
+	Days       int         `json:"days,omitempty"`
+	Amount     Money       `json:"amount,omitempty"`
+	Percentage json.Number `json:"percentage,omitempty"`
+}
+
+func (c BoletoCharge) String() string {
+	if c.Percentage > 0 {
+		return fmt.Sprintf("%v%% after %v days", c.Percentage, c.Days)
+	}
+	return fmt.Sprintf("%v after %v days", c.Amount, c.Days)
+}
+
+func (c BoletoCharge) valid() bool {
+	return c.Days >= 1 && c.Amount >= 0 && c.Percentage >= 0 && c.Percentage <= MAX_INTEREST_RATE && (c.Amount > 0) != (c.Percentage > 0)
+}
+
+func (c BoletoCharge) request() *boletoCharge {
+	request := &boletoCharge{Days: c.Days, Amount: c.Amount}
+	if c.Percentage > 0 {
+		request.Percentage = json.Number(c.Percentage.String())
+	}
+	return request
+}
+
+// BusinessDay reports whether date is a banking day in Brazil, which is not
+// a weekend nor a national or banking holiday. Boletos due on other days can
+// be paid on the next business day without charges.
+func BusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+
+	year, month, day := date.Date()
+	for _, holiday := range holidays(year) {
+		if holiday.month == month && holiday.day == day {
+			return false
+		}
+	}
+	return true
+}
+
+// NextBusinessDay returns date when it is a business day, or the first
+// business day after it.
+func NextBusinessDay(date time.Time) time.Time {
+	for !BusinessDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// AddBusinessDays returns the business day days business days after date,
+// such as the expiration date of a boleto.
+func AddBusinessDays(date time.Time, days int) time.Time {
+	for ; days > 0; days-- {
+		date = NextBusinessDay(date.AddDate(0, 0, 1))
+	}
+	return date
+}
+
+type holiday struct {
+	month time.Month
+	day   int
+}
+
+// holidays returns the national holidays and the banking holidays of
+// Carnival of year.
+func holidays(year int) []holiday {
+	list := []holiday{
+		{time.January, 1}, {time.April, 21}, {time.May, 1}, {time.September, 7},
+		{time.October, 12}, {time.November, 2}, {time.November, 15}, {time.December, 25},
+	}
+
+	if year >= 2024 {
+		list = append(list, holiday{time.November, 20})
+	}
+
+	easter := easter(year)
+	for _, offset := range []int{-48, -47, -2, 60} {
+		date := easter.AddDate(0, 0, offset)
+		list = append(list, holiday{date.Month(), date.Day()})
+	}
+
+	return list
+}
+
+// easter returns the Easter Sunday of year, by the anonymous Gregorian
+// algorithm.
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,62 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEaster(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal(date(2021, time.April, 4), easter(2021))
+	assertTest.Equal(date(2024, time.March, 31), easter(2024))
+	assertTest.Equal(date(2026, time.April, 5), easter(2026))
+}
+
+func TestBusinessDay(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		business bool
+	}{
+		{date(2024, time.March, 1), true},
+		{date(2024, time.March, 2), false},
+		{date(2024, time.March, 3), false},
+		{date(2024, time.January, 1), false},
+		{date(2024, time.February, 12), false},
+		{date(2024, time.February, 13), false},
+		{date(2024, time.February, 14), true},
+		{date(2024, time.March, 29), false},
+		{date(2024, time.May, 30), false},
+		{date(2024, time.November, 20), false},
+		{date(2023, time.November, 20), true},
+		{date(2024, time.December, 25), false},
+	}
+
+	for _, test := range tests {
+		assert.New(t).Equal(test.business, BusinessDay(test.date), test.date.Format(BOLETO_DATE_LAYOUT))
+	}
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal(date(2024, time.March, 1), NextBusinessDay(date(2024, time.March, 1)))
+	assertTest.Equal(date(2024, time.March, 4), NextBusinessDay(date(2024, time.March, 2)))
+	assertTest.Equal(date(2024, time.February, 14), NextBusinessDay(date(2024, time.February, 10)))
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal(date(2024, time.March, 1), AddBusinessDays(date(2024, time.March, 1), 0))
+	assertTest.Equal(date(2024, time.March, 4), AddBusinessDays(date(2024, time.March, 1), 1))
+	assertTest.Equal(date(2024, time.April, 2), AddBusinessDays(date(2024, time.March, 27), 3))
+}
+
+func TestBoletoChargeString(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("2.00% after 1 days", BoletoCharge{Days: 1, Percentage: 200}.String())
+	assertTest.Equal("R$ 0,50 after 3 days", BoletoCharge{Days: 3, Amount: 50}.String())
+}
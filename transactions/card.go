@@ -0,0 +1,133 @@
+package transactions
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// cvvRegex is the format of the security code of any brand. The length of
+// each brand is given by CVVLength.
+var cvvRegex = regexp.MustCompile(`^\d{3,4}$`)
+
+// CardBrand is the card network, named as in the Pagar.me card_brand field.
+type CardBrand string
+
+const (
+	UNKNOWN_BRAND CardBrand = ""
+	VISA          CardBrand = "visa"
+	MASTERCARD    CardBrand = "mastercard"
+	ELO           CardBrand = "elo"
+	HIPERCARD     CardBrand = "hipercard"
+	AMEX          CardBrand = "amex"
+	DINERS        CardBrand = "diners"
+	DISCOVER      CardBrand = "discover"
+	JCB           CardBrand = "jcb"
+	AURA          CardBrand = "aura"
+)
+
+// binRange is an inclusive range of card number prefixes with the same
+// number of digits.
+type binRange struct {
+	from int
+	to   int
+}
+
+type brandRule struct {
+	brand   CardBrand
+	ranges  []binRange
+	lengths []int
+}
+
+// brandRules are checked in order, so the ranges of Elo, Hipercard and Aura
+// come before the wider Visa, Mastercard, Diners and Discover ranges that
+// contain them.
+var brandRules = []brandRule{
+	{ELO, []binRange{
+		{401178, 401179}, {431274, 431274}, {438935, 438935}, {451416, 451416},
+		{457393, 457393}, {457631, 457632}, {504175, 504175}, {506699, 506778},
+		{509000, 509999}, {627780, 627780}, {636297, 636297}, {636368, 636368},
+		{650031, 650033}, {650035, 650051}, {650405, 650439}, {650485, 650538},
+		{650541, 650598}, {650700, 650718}, {650720, 650727}, {650901, 650978},
+		{651652, 651679}, {655000, 655019}, {655021, 655058},
+	}, []int{16}},
+	{HIPERCARD, []binRange{{606282, 606282}, {3841, 3841}}, []int{13, 16, 19}},
+	{AURA, []binRange{{50, 50}}, []int{16, 17, 18, 19}},
+	{AMEX, []binRange{{34, 34}, {37, 37}}, []int{15}},
+	{DINERS, []binRange{{300, 305}, {36, 36}, {38, 39}}, []int{14, 16}},
+	{DISCOVER, []binRange{{6011, 6011}, {622126, 622925}, {644, 649}, {65, 65}}, []int{16, 19}},
+	{JCB, []binRange{{3528, 3589}}, []int{16, 19}},
+	{VISA, []binRange{{4, 4}}, []int{13, 16, 19}},
+	{MASTERCARD, []binRange{{51, 55}, {2221, 2720}}, []int{16}},
+}
+
+func (b CardBrand) String() string {
+	return string(b)
+}
+
+// CVVLength is the number of digits of the security code, 4 for Amex and 3
+// for the other brands.
+func (b CardBrand) CVVLength() int {
+	if b == AMEX {
+		return 4
+	}
+	return 3
+}
+
+// ValidLength reports whether the brand issues card numbers with length
+// digits.
+func (b CardBrand) ValidLength(length int) bool {
+	for _, rule := range brandRules {
+		if rule.brand != b {
+			continue
+		}
+		for _, valid := range rule.lengths {
+			if valid == length {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectCardBrand returns the brand of a card number by its BIN, the
+// leading digits, or UNKNOWN_BRAND.
+func DetectCardBrand(number string) CardBrand {
+	for _, rule := range brandRules {
+		for _, r := range rule.ranges {
+			digits := len(strconv.Itoa(r.from))
+			if len(number) < digits {
+				continue
+			}
+			prefix, err := strconv.Atoi(number[:digits])
+			if err == nil && prefix >= r.from && prefix <= r.to {
+				return rule.brand
+			}
+		}
+	}
+	return UNKNOWN_BRAND
+}
+
+// ValidLuhn checks the mod 10 check digit of a card number.
+func ValidLuhn(number string) bool {
+	if number == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
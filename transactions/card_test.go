@@ -0,0 +1,56 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetectCardBrand(t *testing.T) {
+	tests := []struct {
+		number string
+		brand  CardBrand
+	}{
+		{"4111111111111111", VISA},
+		{"5555555555554444", MASTERCARD},
+		{"2223000048400011", MASTERCARD},
+		{"378282246310005", AMEX},
+		{"6362970000457013", ELO},
+		{"5067224275805500", ELO},
+		{"4011780000000006", ELO},
+		{"6062825624254001", HIPERCARD},
+		{"3841001111222233334", HIPERCARD},
+		{"5078601870000127985", AURA},
+		{"30569309025904", DINERS},
+		{"38520000023237", DINERS},
+		{"6011111111111117", DISCOVER},
+		{"3530111333300000", JCB},
+		{"9999999999999995", UNKNOWN_BRAND},
+		{"", UNKNOWN_BRAND},
+	}
+
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			assertTest := assert.New(t)
+			assertTest.Equal(test.brand, DetectCardBrand(test.number))
+		})
+	}
+}
+
+func TestValidLuhn(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.True(ValidLuhn("4111111111111111"))
+	assertTest.True(ValidLuhn("378282246310005"))
+	assertTest.False(ValidLuhn("4111111111111112"))
+	assertTest.False(ValidLuhn("4111 1111 1111 1111"))
+	assertTest.False(ValidLuhn(""))
+}
+
+func TestCardBrandRules(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal(4, AMEX.CVVLength())
+	assertTest.Equal(3, VISA.CVVLength())
+	assertTest.Equal(3, UNKNOWN_BRAND.CVVLength())
+	assertTest.True(VISA.ValidLength(13))
+	assertTest.False(AMEX.ValidLength(16))
+	assertTest.False(UNKNOWN_BRAND.ValidLength(16))
+}
@@ -0,0 +1,164 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PATH_CARDS = "/cards"
+const PATH_CARD_ID = "/cards/%v"
+
+var cardIDRegex = regexp.MustCompile(`^card_[0-9A-Za-z]+$`)
+
+// CardData is the card encrypted in a card hash. ExpirationDate is given as
+// in TransactionBuilder.CardExpirationDate.
+type CardData struct {
+	Number         string
+	HolderName     string
+	ExpirationDate string
+	CVV            string
+}
+
+// cardRequest is the request body of CreateCard.
+type cardRequest struct {
+	CustomerID int    `json:"customer_id,omitempty"`
+	CardHash   string `json:"card_hash"`
+}
+
+// validate checks the card as the card setters of TransactionBuilder do and
+// returns it with the expiration date as MMYY.
+func (card CardData) validate(now time.Time) (CardData, error) {
+	brand := DetectCardBrand(card.Number)
+	if !ValidLuhn(card.Number) || !brand.ValidLength(len(card.Number)) {
+		return card, &InvalidValueError{"CardNumber", card.Number}
+	}
+
+	if strings.TrimSpace(card.HolderName) == "" {
+		return card, &InvalidValueError{"CardHolderName", card.HolderName}
+	}
+
+	expiry, err := ParseCardExpiry(card.ExpirationDate)
+	if err != nil {
+		return card, err
+	}
+	if expiry.Expired(now) {
+		return card, &InvalidValueError{"CardExpirationDate", card.ExpirationDate}
+	}
+	card.ExpirationDate = expiry.String()
+
+	if !regexp.MustCompile(fmt.Sprintf(`^\d{%v}$`, brand.CVVLength())).MatchString(card.CVV) {
+		return card, &InvalidValueError{"CardCVV", card.CVV}
+	}
+
+	return card, nil
+}
+
+// CreateCard stores the card of cardHash for the customer customerID, so it
+// can be charged again with TransactionBuilder.CardID.
+func (c *client) CreateCard(customerID int, cardHash string) (*Card, error) {
+	return c.CreateCardContext(context.Background(), customerID, cardHash)
+}
+
+func (c *client) CreateCardContext(ctx context.Context, customerID int, cardHash string) (*Card, error) {
+	if cardHash == "" {
+		return nil, &MissingValueError{"CardHash"}
+	}
+
+	result := Card{}
+	payload := cardRequest{CustomerID: customerID, CardHash: cardHash}
+	if err := c.request(ctx, "POST", PATH_CARDS, payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CreateCardWithCardHash validates card and stores it for the customer
+// customerID, encrypted with the cached public key. The key is renewed once
+// when Pagar.me rejects the hash, as in ExecuteWithCardHash.
+func (c *client) CreateCardWithCardHash(customerID int, card CardData) (*Card, error) {
+	return c.CreateCardWithCardHashContext(context.Background(), customerID, card)
+}
+
+func (c *client) CreateCardWithCardHashContext(ctx context.Context, customerID int, card CardData) (*Card, error) {
+	card, err := card.validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		key, err := c.keys.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cardHash, err := card.CardHash(key)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := c.CreateCardContext(ctx, customerID, cardHash)
+		if attempt == 1 && cardHashRejected(err) {
+			c.log().Warn("Card hash rejected, recovering a new public key", "key_id", key.Id)
+			c.keys.invalidate(key)
+			continue
+		}
+
+		return result, err
+	}
+}
+
+func (c *client) GetCard(id string) (*Card, error) {
+	return c.GetCardContext(context.Background(), id)
+}
+
+func (c *client) GetCardContext(ctx context.Context, id string) (*Card, error) {
+	result := Card{}
+
+	if err := c.request(ctx, "GET", fmt.Sprintf(PATH_CARD_ID, url.PathEscape(id)), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CardIterator walks the result of ListCards like TransactionIterator.
+type CardIterator struct {
+	pager
+	buffer  []Card
+	current Card
+}
+
+func (it *CardIterator) Next() bool {
+	if len(it.buffer) == 0 && !it.next(&it.buffer) {
+		return false
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+func (it *CardIterator) Card() Card {
+	return it.current
+}
+
+func (it *CardIterator) Err() error {
+	return it.err
+}
+
+// ListCards returns an iterator over the cards stored for the customer
+// customerID.
+func (c *client) ListCards(customerID int) *CardIterator {
+	return c.ListCardsContext(context.Background(), customerID)
+}
+
+func (c *client) ListCardsContext(ctx context.Context, customerID int) *CardIterator {
+	q := url.Values{}
+	q.Add("customer_id", strconv.Itoa(customerID))
+	return &CardIterator{pager: pager{ctx: ctx, client: c, path: PATH_CARDS, query: q}}
+}
@@ -0,0 +1,197 @@
+package transactions
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cardData() CardData {
+	return CardData{Number: "4111111111111111", HolderName: "Leandro", ExpirationDate: "10/30", CVV: "123"}
+}
+
+func TestCardDataValidate(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		card func(card *CardData)
+		err  string
+	}{
+		{func(card *CardData) {}, ""},
+		{func(card *CardData) { card.Number = "4111111111111112" }, "CardNumber is invalid. Value: 4111111111111112"},
+		{func(card *CardData) { card.HolderName = " " }, "CardHolderName is invalid. Value:  "},
+		{func(card *CardData) { card.ExpirationDate = "0224" }, "CardExpirationDate is invalid. Value: 0224"},
+		{func(card *CardData) { card.CVV = "1234" }, "CardCVV is invalid. Value: 1234"},
+	}
+
+	for _, test := range tests {
+		card := cardData()
+		test.card(&card)
+		valid, err := card.validate(now)
+		if test.err == "" {
+			assert.New(t).Nil(err)
+			assert.New(t).Equal("1030", valid.ExpirationDate)
+		} else {
+			assert.New(t).EqualError(err, test.err)
+		}
+	}
+}
+
+func TestCardDataCardHash(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	cardHash, err := cardData().CardHash(PublicKey{Id: 3, PublicKey: pemPublicKey(t, &privateKey.PublicKey)})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	parts := strings.SplitN(cardHash, "_", 2)
+	assertTest.Equal("3", parts[0])
+	encrypted, _ := base64.StdEncoding.DecodeString(parts[1])
+	decrypted, err := rsa.DecryptPKCS1v15(rand.Reader, privateKey, encrypted)
+	assertTest.Nil(err)
+	card, _ := url.ParseQuery(string(decrypted))
+	assertTest.Equal("4111111111111111", card.Get("card_number"))
+	assertTest.Equal("Leandro", card.Get("card_holder_name"))
+	assertTest.Equal("123", card.Get("card_cvv"))
+}
+
+func TestCreateCard(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "card", "id": "card_abc", "brand": "visa", "last_digits": "1111"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.CreateCard(1001, "1_abc")
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("card_abc", result.ID)
+	assertTest.Equal(VISA, result.Brand)
+	assertTest.Equal(http.MethodPost, requests[0].Method)
+	assertTest.Equal("/cards", requests[0].URL.Path)
+	assertTest.Equal(float64(1001), bodies[0]["customer_id"])
+	assertTest.Equal("1_abc", bodies[0]["card_hash"])
+}
+
+func TestCreateCardRequiresCardHash(t *testing.T) {
+	client := NewClient(WithAPIKey("ak_test_key"))
+	_, err := client.CreateCard(1001, "")
+
+	assert.New(t).EqualError(err, "CardHash is required")
+}
+
+func TestGetCard(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "card", "id": "card_abc"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.GetCard("card_abc")
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("card_abc", result.ID)
+	assertTest.Equal(http.MethodGet, requests[0].Method)
+	assertTest.Equal("/cards/card_abc", requests[0].URL.Path)
+}
+
+func TestListCards(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `[{"id": "card_1"}, {"id": "card_2"}]`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListCards(1001)
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Card().ID)
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal([]string{"card_1", "card_2"}, ids)
+	assertTest.Equal("/cards", requests[0].URL.Path)
+	assertTest.Equal("1001", requests[0].URL.Query().Get("customer_id"))
+}
+
+func TestTransactionBuildCardID(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardID("card_ci6y37h16wnn6qfwrbdx6sw7n")
+	_, err := tb.CardCVV("1234")
+
+	transaction, buildErr := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Nil(buildErr)
+	assertTest.Equal("card_ci6y37h16wnn6qfwrbdx6sw7n", transaction.CardID)
+	assertTest.Equal("1234", transaction.CardCVV)
+}
+
+func TestTransactionBuildCardIDWithoutCVV(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardID("card_abc")
+
+	_, err := tb.Build()
+
+	assert.New(t).Nil(err)
+}
+
+func TestCardIDInvalid(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.CardID("abc")
+
+	assert.New(t).EqualError(err, "CardID is invalid. Value: abc")
+}
+
+func TestTransactionBuildCardIDAndCardNumber(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardID("card_abc")
+	tb.CardNumber("4111111111111111")
+
+	_, err := tb.Build()
+
+	assert.New(t).EqualError(err, "Invalid transaction: CardID is invalid. Value: card_abc")
+}
+
+func TestTransactionBuildBoletoCardID(t *testing.T) {
+	tb := boletoBuilder()
+	tb.CardID("card_abc")
+
+	_, err := tb.Build()
+
+	assert.New(t).EqualError(err, "Invalid transaction: CardID is invalid. Value: card_abc")
+}
+
+func TestExecuteWithCardHashCardID(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "paid"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.ExecuteWithCardHash(Transaction{Amount: 200, CardID: "card_abc", CardCVV: "123"}, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(PAID, result.Status)
+	assertTest.Len(requests, 1)
+	assertTest.Equal("/transactions", requests[0].URL.Path)
+	assertTest.Equal("card_abc", bodies[0]["card_id"])
+	assertTest.Nil(bodies[0]["card_hash"])
+}
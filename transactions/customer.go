@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var e164Regex = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+var zipcodeRegex = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+
+// states are the codes of the Brazilian states and the Federal District.
+var states = map[string]bool{
+	"AC": true, "AL": true, "AP": true, "AM": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MT": true, "MS": true, "MG": true, "PA": true,
+	"PB": true, "PR": true, "PE": true, "PI": true, "RJ": true, "RN": true, "RS": true,
+	"RO": true, "RR": true, "SC": true, "SP": true, "SE": true, "TO": true,
+}
+
+// NormalizePhoneNumber removes the spaces, dashes, dots and parentheses of a
+// phone number, which must then be in E.164 format, such as +5511999998888.
+// Brazilian numbers (+55) must have the area code and 8 or 9 digits.
+func NormalizePhoneNumber(value string) (string, bool) {
+	number := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(value)
+
+	if !e164Regex.MatchString(number) {
+		return "", false
+	}
+
+	if strings.HasPrefix(number, "+55") && len(number) != 13 && len(number) != 14 {
+		return "", false
+	}
+
+	return number, true
+}
+
+// validEmail reports whether value is an email address without a display
+// name.
+func validEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// NormalizeZipcode returns the 8 digits of a CEP, such as 01310-100.
+func NormalizeZipcode(value string) (string, bool) {
+	if !zipcodeRegex.MatchString(value) {
+		return "", false
+	}
+	return strings.Replace(value, "-", "", 1), true
+}
+
+// ValidState reports whether value is the code of a Brazilian state, such
+// as SP or sp.
+func ValidState(value string) bool {
+	return states[strings.ToUpper(value)]
+}
+
+// validAddress checks the fields required by Pagar.me and, for Brazilian
+// addresses, the CEP and the state. It returns the address with the CEP
+// normalized and the error of the first invalid field, named after param.
+func validAddress(param string, address Address) (Address, *InvalidValueError) {
+	required := []struct {
+		field string
+		value string
+	}{
+		{"Street", address.Street},
+		{"StreetNumber", address.StreetNumber},
+		{"Neighborhood", address.Neighborhood},
+		{"City", address.City},
+		{"State", address.State},
+		{"Zipcode", address.Zipcode},
+		{"Country", address.Country},
+	}
+
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return address, &InvalidValueError{param + "." + field.field, field.value}
+		}
+	}
+
+	if !strings.EqualFold(address.Country, "br") {
+		return address, nil
+	}
+
+	zipcode, ok := NormalizeZipcode(address.Zipcode)
+	if !ok {
+		return address, &InvalidValueError{param + ".Zipcode", address.Zipcode}
+	}
+
+	if !ValidState(address.State) {
+		return address, &InvalidValueError{param + ".State", address.State}
+	}
+
+	address.Zipcode = zipcode
+	address.State = strings.ToLower(address.State)
+	address.Country = strings.ToLower(address.Country)
+	return address, nil
+}
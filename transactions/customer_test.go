@@ -0,0 +1,109 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		number string
+		ok     bool
+	}{
+		{"+5511999998888", "+5511999998888", true},
+		{"+55 (11) 99999-8888", "+5511999998888", true},
+		{"+55 11 3333-4444", "+551133334444", true},
+		{"+1 415 555 2671", "+14155552671", true},
+		{"11999998888", "", false},
+		{"+55 11 9999", "", false},
+		{"+0 11 99999-8888", "", false},
+		{"+55 11 99999-888a", "", false},
+	}
+
+	for _, test := range tests {
+		number, ok := NormalizePhoneNumber(test.value)
+		assertTest := assert.New(t)
+		assertTest.Equal(test.ok, ok, test.value)
+		assertTest.Equal(test.number, number, test.value)
+	}
+}
+
+func TestNormalizeZipcode(t *testing.T) {
+	assertTest := assert.New(t)
+
+	zipcode, ok := NormalizeZipcode("01310-100")
+	assertTest.True(ok)
+	assertTest.Equal("01310100", zipcode)
+
+	zipcode, ok = NormalizeZipcode("01310100")
+	assertTest.True(ok)
+	assertTest.Equal("01310100", zipcode)
+
+	for _, value := range []string{"0131010", "01310-1000", "01.310-100", "abcde-fgh"} {
+		_, ok = NormalizeZipcode(value)
+		assertTest.False(ok, value)
+	}
+}
+
+func TestValidState(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.True(ValidState("SP"))
+	assertTest.True(ValidState("df"))
+	assertTest.False(ValidState("XX"))
+	assertTest.False(ValidState("São Paulo"))
+}
+
+func address() Address {
+	return Address{
+		Street:       "Avenida Paulista",
+		StreetNumber: "1000",
+		Neighborhood: "Bela Vista",
+		City:         "São Paulo",
+		State:        "SP",
+		Zipcode:      "01310-100",
+		Country:      "BR",
+	}
+}
+
+func TestValidAddress(t *testing.T) {
+	valid, err := validAddress("Billing.Address", address())
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("01310100", valid.Zipcode)
+	assertTest.Equal("sp", valid.State)
+	assertTest.Equal("br", valid.Country)
+}
+
+func TestValidAddressInvalid(t *testing.T) {
+	tests := []struct {
+		change func(*Address)
+		err    string
+	}{
+		{func(a *Address) { a.Street = "" }, "Billing.Address.Street is invalid. Value: "},
+		{func(a *Address) { a.Zipcode = "0131" }, "Billing.Address.Zipcode is invalid. Value: 0131"},
+		{func(a *Address) { a.State = "XX" }, "Billing.Address.State is invalid. Value: XX"},
+		{func(a *Address) { a.Country = "" }, "Billing.Address.Country is invalid. Value: "},
+	}
+
+	for _, test := range tests {
+		value := address()
+		test.change(&value)
+		_, err := validAddress("Billing.Address", value)
+		assert.New(t).EqualError(err, test.err)
+	}
+}
+
+func TestValidAddressForeign(t *testing.T) {
+	value := address()
+	value.Country = "us"
+	value.State = "CA"
+	value.Zipcode = "94103"
+
+	valid, err := validAddress("Shipping.Address", value)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("94103", valid.Zipcode)
+}
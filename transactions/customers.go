@@ -0,0 +1,157 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"pagarme/documents"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const PATH_CUSTOMERS = "/customers"
+const PATH_CUSTOMER_ID = "/customers/%v"
+
+var countryRegex = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+
+// Customer is the request body of CreateCustomer. Document is a CPF or CNPJ,
+// formatted or not, which also chooses the customer type. Country defaults
+// to br.
+type Customer struct {
+	ExternalID   string   `json:"external_id"`
+	Name         string   `json:"name"`
+	Email        string   `json:"email,omitempty"`
+	Country      string   `json:"country"`
+	PhoneNumbers []string `json:"phone_numbers,omitempty"`
+	Birthday     *Date    `json:"birthday,omitempty"`
+	Document     string   `json:"-"`
+}
+
+// customerRequest is a Customer validated by validCustomer, with its
+// document as sent to Pagar.me.
+type customerRequest struct {
+	Customer
+	Type      string     `json:"type"`
+	Documents []document `json:"documents"`
+}
+
+// validCustomer checks the fields of customer as the customer setters of
+// TransactionBuilder do and normalizes its phone numbers and document.
+func validCustomer(customer Customer, now time.Time) (customerRequest, error) {
+	request := customerRequest{Customer: customer}
+
+	if strings.TrimSpace(customer.ExternalID) == "" {
+		return request, &MissingValueError{"ExternalID"}
+	}
+	if strings.TrimSpace(customer.Name) == "" {
+		return request, &MissingValueError{"Name"}
+	}
+	if customer.Email != "" && !validEmail(customer.Email) {
+		return request, &InvalidValueError{"Email", customer.Email}
+	}
+
+	if request.Country == "" {
+		request.Country = "br"
+	}
+	if !countryRegex.MatchString(request.Country) {
+		return request, &InvalidValueError{"Country", request.Country}
+	}
+
+	request.PhoneNumbers = nil
+	for i, value := range customer.PhoneNumbers {
+		number, ok := NormalizePhoneNumber(value)
+		if !ok {
+			return request, &InvalidValueError{fmt.Sprintf("PhoneNumber[%v]", i), value}
+		}
+		request.PhoneNumbers = append(request.PhoneNumbers, number)
+	}
+
+	if birthday := customer.Birthday; birthday != nil && (birthday.Year() < 1900 || !birthday.Before(now)) {
+		return request, &InvalidValueError{"Birthday", birthday.String()}
+	}
+
+	if customer.Document == "" {
+		return request, &MissingValueError{"Document.Number"}
+	}
+	documentType, number, err := documents.Validate(customer.Document)
+	if err != nil {
+		return request, &InvalidValueError{"Document.Number", customer.Document}
+	}
+	request.Documents = []document{{DocumentType: documentType.String(), Number: number}}
+
+	request.Type = INDIVIDUAL.String()
+	if documentType == CNPJ {
+		request.Type = CORPORATION.String()
+	}
+
+	return request, nil
+}
+
+// CreateCustomer registers a customer, whose id is used to store cards with
+// CreateCard.
+func (c *client) CreateCustomer(customer Customer) (*CustomerResponse, error) {
+	return c.CreateCustomerContext(context.Background(), customer)
+}
+
+func (c *client) CreateCustomerContext(ctx context.Context, customer Customer) (*CustomerResponse, error) {
+	request, err := validCustomer(customer, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := CustomerResponse{}
+	if err := c.request(ctx, "POST", PATH_CUSTOMERS, request, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *client) GetCustomer(id int) (*CustomerResponse, error) {
+	return c.GetCustomerContext(context.Background(), id)
+}
+
+func (c *client) GetCustomerContext(ctx context.Context, id int) (*CustomerResponse, error) {
+	result := CustomerResponse{}
+
+	if err := c.request(ctx, "GET", fmt.Sprintf(PATH_CUSTOMER_ID, id), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CustomerIterator walks the result of ListCustomers like
+// TransactionIterator.
+type CustomerIterator struct {
+	pager
+	buffer  []CustomerResponse
+	current CustomerResponse
+}
+
+func (it *CustomerIterator) Next() bool {
+	if len(it.buffer) == 0 && !it.next(&it.buffer) {
+		return false
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+func (it *CustomerIterator) Customer() CustomerResponse {
+	return it.current
+}
+
+func (it *CustomerIterator) Err() error {
+	return it.err
+}
+
+// ListCustomers returns an iterator over every customer. No request is made
+// until Next is called.
+func (c *client) ListCustomers() *CustomerIterator {
+	return c.ListCustomersContext(context.Background())
+}
+
+func (c *client) ListCustomersContext(ctx context.Context) *CustomerIterator {
+	return &CustomerIterator{pager: pager{ctx: ctx, client: c, path: PATH_CUSTOMERS}}
+}
@@ -0,0 +1,115 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func customer() Customer {
+	return Customer{
+		ExternalID:   "42",
+		Name:         "Leandro",
+		Email:        "leandro@example.com",
+		PhoneNumbers: []string{"+55 (11) 99999-8888"},
+		Document:     "251.854.650-26",
+	}
+}
+
+func TestValidCustomer(t *testing.T) {
+	request, err := validCustomer(customer(), time.Now())
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("br", request.Country)
+	assertTest.Equal([]string{"+5511999998888"}, request.PhoneNumbers)
+	assertTest.Equal("individual", request.Type)
+	assertTest.Equal([]document{{DocumentType: "cpf", Number: "25185465026"}}, request.Documents)
+
+	corporation := customer()
+	corporation.Document = "11.222.333/0001-81"
+	request, err = validCustomer(corporation, time.Now())
+	assertTest.Nil(err)
+	assertTest.Equal("corporation", request.Type)
+}
+
+func TestValidCustomerInvalid(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	future := NewDate(2024, time.March, 2)
+	tests := []struct {
+		customer func(customer *Customer)
+		err      string
+	}{
+		{func(customer *Customer) { customer.ExternalID = "" }, "ExternalID is required"},
+		{func(customer *Customer) { customer.Name = " " }, "Name is required"},
+		{func(customer *Customer) { customer.Email = "Leandro <leandro@example.com>" }, "Email is invalid. Value: Leandro <leandro@example.com>"},
+		{func(customer *Customer) { customer.Country = "bra" }, "Country is invalid. Value: bra"},
+		{func(customer *Customer) { customer.PhoneNumbers = []string{"+5511999998888", "11999998888"} }, "PhoneNumber[1] is invalid. Value: 11999998888"},
+		{func(customer *Customer) { customer.Birthday = &future }, "Birthday is invalid. Value: 2024-03-02"},
+		{func(customer *Customer) { customer.Document = "" }, "Document.Number is required"},
+		{func(customer *Customer) { customer.Document = "111.111.111-11" }, "Document.Number is invalid. Value: 111.111.111-11"},
+	}
+
+	for _, test := range tests {
+		value := customer()
+		test.customer(&value)
+		_, err := validCustomer(value, now)
+		assert.New(t).EqualError(err, test.err)
+	}
+}
+
+func TestCreateCustomer(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "customer", "id": 1001, "external_id": "42"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.CreateCustomer(customer())
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(1001, result.ID)
+	assertTest.Equal(http.MethodPost, requests[0].Method)
+	assertTest.Equal("/customers", requests[0].URL.Path)
+	assertTest.Equal("42", bodies[0]["external_id"])
+	assertTest.Equal("individual", bodies[0]["type"])
+	assertTest.Nil(bodies[0]["Document"])
+	assertTest.Equal([]interface{}{map[string]interface{}{"type": "cpf", "number": "25185465026"}}, bodies[0]["documents"])
+}
+
+func TestGetCustomer(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "customer", "id": 1001}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.GetCustomer(1001)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(1001, result.ID)
+	assertTest.Equal("/customers/1001", requests[0].URL.Path)
+}
+
+func TestListCustomers(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `[{"id": 1001}, {"id": 1002}]`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListCustomers()
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Customer().ID)
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal([]int{1001, 1002}, ids)
+	assertTest.Equal("/customers", requests[0].URL.Path)
+}
@@ -1,10 +1,11 @@
 package transactions
 
-import "fmt"
-
-type error interface {
-	Error() string
-}
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 type InternalError struct {
 	Path string
@@ -15,10 +16,185 @@ type InvalidValueError struct {
 	Value      string
 }
 
+type MissingValueError struct {
+	ValueParam string
+}
+
+// BuildError lists every invalid or missing field found by
+// TransactionBuilder.Build.
+type BuildError struct {
+	Errors []error
+}
+
+// AmountMismatchError is returned by TransactionBuilder.Build when the total
+// of the items plus the shipping fee is not the amount of the transaction.
+type AmountMismatchError struct {
+	Amount Money
+	Items  Money
+	Fee    Money
+}
+
+// SplitSumError is returned when the percentages of the split rules don't
+// sum to 100, or their amounts, in centavos, don't sum to the amount of the
+// transaction.
+type SplitSumError struct {
+	Percentage bool
+	Sum        int64
+	Total      int64
+}
+
+// DocumentOwnershipError is returned when the bank account given to a
+// recipient belongs to another document than its current account.
+type DocumentOwnershipError struct {
+	RecipientID string
+	Document    string
+}
+
+// PEMParseError is returned when the public key is not a PEM encoded PKIX
+// key. Err is nil when no PEM block is found.
+type PEMParseError struct {
+	Err error
+}
+
+// NonRSAKeyError is returned when the public key is valid but not an RSA key.
+type NonRSAKeyError struct {
+	KeyType string
+}
+
+// EncryptionError is returned when the card data can't be encrypted with the
+// public key.
+type EncryptionError struct {
+	Err error
+}
+
+// APIError is returned when Pagar.me answers a request with a non-success
+// status. Errors carries the per-field entries of the response "errors" array.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Errors     []FieldError
+}
+
+type FieldError struct {
+	Type          string `json:"type"`
+	ParameterName string `json:"parameter_name"`
+	Message       string `json:"message"`
+}
+
 func (e *InvalidValueError) Error() string {
 	return fmt.Sprintf("%v is invalid. Value: %v", e.ValueParam, e.Value)
 }
 
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("%v is required", e.ValueParam)
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "Invalid transaction: " + strings.Join(messages, "; ")
+}
+
+func (e *AmountMismatchError) Error() string {
+	return fmt.Sprintf("Amount %v does not match the items %v plus the shipping fee %v", e.Amount, e.Items, e.Fee)
+}
+
+func (e *SplitSumError) Error() string {
+	if e.Percentage {
+		return fmt.Sprintf("Split rule percentages sum to %v%%, not %v%%", e.Sum, e.Total)
+	}
+	return fmt.Sprintf("Split rule amounts sum to %v, not the amount %v", Money(e.Sum), Money(e.Total))
+}
+
+func (e *DocumentOwnershipError) Error() string {
+	return fmt.Sprintf("Bank account of document %v does not belong to the owner of recipient %v", e.Document, e.RecipientID)
+}
+
+func (e *PEMParseError) Error() string {
+	if e.Err == nil {
+		return "failed to parse PEM block containing the public key"
+	}
+	return "failed to parse DER encoded public key: " + e.Err.Error()
+}
+
+func (e *PEMParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NonRSAKeyError) Error() string {
+	return fmt.Sprintf("public key is %v, not RSA", e.KeyType)
+}
+
+func (e *EncryptionError) Error() string {
+	return "failed to EncryptPKCS1v15 " + e.Err.Error()
+}
+
+func (e *EncryptionError) Unwrap() error {
+	return e.Err
+}
+
 func (e *InternalError) Error() string {
 	return fmt.Sprintf("Mundipagg internal error. Path: %v", e.Path)
 }
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Pagar.me error. Status: %v Path: %v", e.StatusCode, e.Path)
+
+	if len(e.Errors) == 0 {
+		return msg
+	}
+
+	details := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		if fieldError.ParameterName == "" {
+			details = append(details, fieldError.Message)
+			continue
+		}
+		details = append(details, fieldError.ParameterName+": "+fieldError.Message)
+	}
+
+	return msg + ". Errors: " + strings.Join(details, "; ")
+}
+
+// IsValidation reports whether the request was rejected because of invalid parameters.
+func (e *APIError) IsValidation() bool {
+	return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+}
+
+// IsAuthentication reports whether the request was rejected because of the API key.
+func (e *APIError) IsAuthentication() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// Field returns the error reported for the given parameter, if any.
+func (e *APIError) Field(parameterName string) (FieldError, bool) {
+	for _, fieldError := range e.Errors {
+		if fieldError.ParameterName == parameterName {
+			return fieldError, true
+		}
+	}
+	return FieldError{}, false
+}
+
+func newAPIError(res *http.Response, path string) *APIError {
+	body := struct {
+		Errors []FieldError `json:"errors"`
+	}{}
+	json.NewDecoder(res.Body).Decode(&body)
+
+	return &APIError{
+		StatusCode: res.StatusCode,
+		Path:       path,
+		Errors:     body.Errors,
+	}
+}
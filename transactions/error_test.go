@@ -1,6 +1,8 @@
 package transactions
 
 import (
+	"crypto/rsa"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -16,3 +18,79 @@ func TestInvalidValueError(t *testing.T) {
 	assertTest := assert.New(t)
 	assertTest.Equal("param is invalid. Value: value", err.Error())
 }
+
+func TestAPIError(t *testing.T) {
+	err := APIError{StatusCode: 400, Path: "/transactions", Errors: []FieldError{
+		{Type: "invalid_parameter", ParameterName: "amount", Message: "valor inválido"},
+		{Type: "invalid_parameter", Message: "erro geral"},
+	}}
+	assertTest := assert.New(t)
+	assertTest.Equal("Pagar.me error. Status: 400 Path: /transactions. Errors: amount: valor inválido; erro geral", err.Error())
+	assertTest.True(err.IsValidation())
+	assertTest.False(err.IsAuthentication())
+}
+
+func TestAPIErrorWithoutErrors(t *testing.T) {
+	err := APIError{StatusCode: 401, Path: "/transactions"}
+	assertTest := assert.New(t)
+	assertTest.Equal("Pagar.me error. Status: 401 Path: /transactions", err.Error())
+	assertTest.True(err.IsAuthentication())
+	assertTest.False(err.IsValidation())
+}
+
+func TestAPIErrorField(t *testing.T) {
+	err := APIError{StatusCode: 400, Errors: []FieldError{{Type: "invalid_parameter", ParameterName: "card_hash", Message: "card_hash inválido"}}}
+	fieldError, found := err.Field("card_hash")
+
+	assertTest := assert.New(t)
+	assertTest.True(found)
+	assertTest.Equal("card_hash inválido", fieldError.Message)
+
+	_, found = err.Field("amount")
+	assertTest.False(found)
+}
+
+func TestBuildError(t *testing.T) {
+	err := &BuildError{[]error{&InvalidValueError{"CardCVV", "12"}, &MissingValueError{"Amount"}}}
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: CardCVV is invalid. Value: 12; Amount is required")
+}
+
+func TestAmountMismatchError(t *testing.T) {
+	err := &AmountMismatchError{Amount: 5000, Items: 4000, Fee: 500}
+	assert.New(t).EqualError(err, "Amount R$ 50,00 does not match the items R$ 40,00 plus the shipping fee R$ 5,00")
+}
+
+func TestSplitSumError(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.EqualError(&SplitSumError{Percentage: true, Sum: 90, Total: 100}, "Split rule percentages sum to 90%, not 100%")
+	assertTest.EqualError(&SplitSumError{Sum: 4000, Total: 5000}, "Split rule amounts sum to R$ 40,00, not the amount R$ 50,00")
+}
+
+func TestDocumentOwnershipError(t *testing.T) {
+	err := &DocumentOwnershipError{RecipientID: "re_1", Document: "11144477735"}
+	assert.New(t).EqualError(err, "Bank account of document 11144477735 does not belong to the owner of recipient re_1")
+}
+
+func TestPEMParseError(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("failed to parse PEM block containing the public key", (&PEMParseError{}).Error())
+
+	cause := errors.New("asn1: syntax error")
+	err := &PEMParseError{cause}
+	assertTest.Equal("failed to parse DER encoded public key: asn1: syntax error", err.Error())
+	assertTest.True(errors.Is(err, cause))
+}
+
+func TestNonRSAKeyError(t *testing.T) {
+	err := NonRSAKeyError{"*ecdsa.PublicKey"}
+	assert.Equal(t, "public key is *ecdsa.PublicKey, not RSA", err.Error())
+}
+
+func TestEncryptionError(t *testing.T) {
+	err := &EncryptionError{rsa.ErrMessageTooLong}
+	assertTest := assert.New(t)
+	assertTest.Equal("failed to EncryptPKCS1v15 crypto/rsa: message too long for RSA key size", err.Error())
+	assertTest.True(errors.Is(err, rsa.ErrMessageTooLong))
+}
@@ -0,0 +1,58 @@
+package transactions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var expiryRegex = regexp.MustCompile(`^(\d{2})(?:(\d{2})|/(\d{2}|\d{4}))$`)
+
+// CardExpiry is the month and year printed on a card. The card is valid up
+// to the last day of that month.
+type CardExpiry struct {
+	month time.Month
+	year  int
+}
+
+// ParseCardExpiry accepts MMYY, MM/YY and MM/YYYY.
+func ParseCardExpiry(value string) (CardExpiry, error) {
+	match := expiryRegex.FindStringSubmatch(value)
+	if match == nil {
+		return CardExpiry{}, &InvalidValueError{"CardExpirationDate", value}
+	}
+
+	digits := match[2] + match[3]
+	month, _ := strconv.Atoi(match[1])
+	year, _ := strconv.Atoi(digits)
+
+	if month < 1 || month > 12 {
+		return CardExpiry{}, &InvalidValueError{"CardExpirationDate", value}
+	}
+
+	if len(digits) == 2 {
+		year += 2000
+	}
+
+	return CardExpiry{time.Month(month), year}, nil
+}
+
+func (e CardExpiry) Month() time.Month {
+	return e.month
+}
+
+func (e CardExpiry) Year() int {
+	return e.year
+}
+
+// Expired reports whether the expiry month is over at now.
+func (e CardExpiry) Expired(now time.Time) bool {
+	end := time.Date(e.year, e.month+1, 1, 0, 0, 0, 0, now.Location())
+	return !now.Before(end)
+}
+
+// String is the MMYY format of the Pagar.me card_expiration_date field.
+func (e CardExpiry) String() string {
+	return fmt.Sprintf("%02d%02d", int(e.month), e.year%100)
+}
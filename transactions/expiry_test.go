@@ -0,0 +1,48 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCardExpiry(t *testing.T) {
+	tests := []struct {
+		value string
+		month time.Month
+		year  int
+	}{
+		{"0121", time.January, 2021},
+		{"12/30", time.December, 2030},
+		{"07/2031", time.July, 2031},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			expiry, err := ParseCardExpiry(test.value)
+
+			assertTest := assert.New(t)
+			assertTest.Nil(err)
+			assertTest.Equal(test.month, expiry.Month())
+			assertTest.Equal(test.year, expiry.Year())
+		})
+	}
+}
+
+func TestParseCardExpiryInvalid(t *testing.T) {
+	for _, value := range []string{"", "0021", "1399", "1/21", "01-21", "012021", "ab/cd"} {
+		_, err := ParseCardExpiry(value)
+
+		assertTest := assert.New(t)
+		assertTest.EqualError(err, "CardExpirationDate is invalid. Value: "+value)
+	}
+}
+
+func TestCardExpiryExpired(t *testing.T) {
+	expiry, _ := ParseCardExpiry("12/20")
+
+	assertTest := assert.New(t)
+	assertTest.False(expiry.Expired(time.Date(2020, time.December, 31, 23, 59, 59, 0, time.UTC)))
+	assertTest.True(expiry.Expired(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assertTest.Equal("1220", expiry.String())
+}
@@ -0,0 +1,165 @@
+package transactions
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const PATH_INSTALLMENTS = "/transactions/calculate_installments_amount"
+const MAX_INSTALLMENTS = 12
+
+// MAX_INTEREST_RATE is the highest rate accepted, 100%.
+const MAX_INTEREST_RATE InterestRate = 10000
+
+var interestRateRegex = regexp.MustCompile(`^(\d{1,3})(?:[.,](\d{1,2}))?$`)
+
+// InterestRate is an interest rate in hundredths of a percent, such as 199
+// for 1,99%.
+type InterestRate int64
+
+// InstallmentOptions describes the installment plans offered at checkout.
+// The first FreeInstallments plans have no interest and every other plan of
+// n installments adds InterestRate n times to the amount, as Pagar.me does.
+type InstallmentOptions struct {
+	Amount           Money
+	MaxInstallments  int
+	FreeInstallments int
+	InterestRate     InterestRate
+}
+
+// Installment is the plan of paying Amount, the total with interest, in
+// Installment parts of InstallmentAmount.
+type Installment struct {
+	Installment       int   `json:"installment"`
+	Amount            Money `json:"amount"`
+	InstallmentAmount Money `json:"installment_amount"`
+}
+
+// ParseInterestRate accepts a percentage of up to MAX_INTEREST_RATE with up
+// to two decimals after a dot or a comma, such as "1.99", "1,99" or "2%".
+// Unlike ParseMoney there are no thousands separators, so "1.999" is refused
+// instead of read as 1999%.
+func ParseInterestRate(value string) (InterestRate, error) {
+	match := interestRateRegex.FindStringSubmatch(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if match == nil {
+		return 0, &InvalidValueError{"InterestRate", value}
+	}
+
+	integer, _ := strconv.ParseInt(match[1], 10, 64)
+	decimals, _ := strconv.ParseInt((match[2] + "00")[:2], 10, 64)
+
+	rate := InterestRate(integer*100 + decimals)
+	if rate > MAX_INTEREST_RATE {
+		return 0, &InvalidValueError{"InterestRate", value}
+	}
+	return rate, nil
+}
+
+// String formats the rate as the interest_rate parameter, such as "1.99".
+func (r InterestRate) String() string {
+	return Money(r).Decimal()
+}
+
+// Set parses value with ParseInterestRate, so *InterestRate can be used as a
+// command line flag value.
+func (r *InterestRate) Set(value string) error {
+	parsed, err := ParseInterestRate(value)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (r *InterestRate) Type() string {
+	return "rate"
+}
+
+func (o InstallmentOptions) validate() error {
+	switch {
+	case o.Amount <= 0:
+		return &InvalidValueError{"Amount", o.Amount.Decimal()}
+	case o.MaxInstallments < 1 || o.MaxInstallments > MAX_INSTALLMENTS:
+		return &InvalidValueError{"MaxInstallments", strconv.Itoa(o.MaxInstallments)}
+	case o.FreeInstallments < 0 || o.FreeInstallments > o.MaxInstallments:
+		return &InvalidValueError{"FreeInstallments", strconv.Itoa(o.FreeInstallments)}
+	case o.InterestRate < 0 || o.InterestRate > MAX_INTEREST_RATE:
+		return &InvalidValueError{"InterestRate", o.InterestRate.String()}
+	}
+	return nil
+}
+
+// CalculateInstallments returns the installment table of options without a
+// request, with the same results as client.CalculateInstallments. Interest
+// and installment amounts are rounded half up to the centavo.
+func CalculateInstallments(options InstallmentOptions) ([]Installment, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	installments := make([]Installment, options.MaxInstallments)
+	for i := range installments {
+		n := int64(i + 1)
+
+		total := options.Amount
+		if i+1 > options.FreeInstallments {
+			total += roundDiv(int64(options.Amount), int64(options.InterestRate)*n, 100*100)
+		}
+
+		installments[i] = Installment{
+			Installment:       i + 1,
+			Amount:            total,
+			InstallmentAmount: roundDiv(int64(total), 1, n),
+		}
+	}
+
+	return installments, nil
+}
+
+// roundDiv returns value * numerator / denominator rounded half up, without
+// overflowing the intermediate product.
+func roundDiv(value int64, numerator int64, denominator int64) Money {
+	product := new(big.Int).Mul(big.NewInt(value), big.NewInt(numerator))
+	product.Add(product, big.NewInt(denominator/2))
+	return Money(product.Quo(product, big.NewInt(denominator)).Int64())
+}
+
+// CalculateInstallments requests the installment table of options from
+// Pagar.me.
+func (c *client) CalculateInstallments(options InstallmentOptions) ([]Installment, error) {
+	return c.CalculateInstallmentsContext(context.Background(), options)
+}
+
+func (c *client) CalculateInstallmentsContext(ctx context.Context, options InstallmentOptions) ([]Installment, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Add("amount", strconv.FormatInt(int64(options.Amount), 10))
+	q.Add("max_installments", strconv.Itoa(options.MaxInstallments))
+	q.Add("free_installments", strconv.Itoa(options.FreeInstallments))
+	q.Add("interest_rate", options.InterestRate.String())
+
+	result := struct {
+		Installments map[string]Installment `json:"installments"`
+	}{}
+	if err := c.request(ctx, "GET", PATH_INSTALLMENTS+"?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+
+	installments := make([]Installment, 0, len(result.Installments))
+	for _, installment := range result.Installments {
+		installments = append(installments, installment)
+	}
+	sort.Slice(installments, func(i, j int) bool {
+		return installments[i].Installment < installments[j].Installment
+	})
+
+	return installments, nil
+}
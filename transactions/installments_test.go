@@ -0,0 +1,117 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCalculateInstallments(t *testing.T) {
+	installments, err := CalculateInstallments(InstallmentOptions{
+		Amount:           NewMoney(100, 0),
+		MaxInstallments:  3,
+		FreeInstallments: 1,
+		InterestRate:     1300,
+	})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Installment{
+		{1, 10000, 10000},
+		{2, 12600, 6300},
+		{3, 13900, 4633},
+	}, installments)
+}
+
+func TestCalculateInstallmentsFractionalRate(t *testing.T) {
+	installments, err := CalculateInstallments(InstallmentOptions{
+		Amount:           NewMoney(33, 33),
+		MaxInstallments:  12,
+		FreeInstallments: 3,
+		InterestRate:     199,
+	})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Len(installments, 12)
+	assertTest.Equal(Installment{3, 3333, 1111}, installments[2])
+	assertTest.Equal(Installment{4, 3598, 900}, installments[3])
+	assertTest.Equal(Installment{12, 4129, 344}, installments[11])
+}
+
+func TestCalculateInstallmentsInvalid(t *testing.T) {
+	tests := []struct {
+		options InstallmentOptions
+		err     string
+	}{
+		{InstallmentOptions{MaxInstallments: 3}, "Amount is invalid. Value: 0.00"},
+		{InstallmentOptions{Amount: 100, MaxInstallments: 13}, "MaxInstallments is invalid. Value: 13"},
+		{InstallmentOptions{Amount: 100}, "MaxInstallments is invalid. Value: 0"},
+		{InstallmentOptions{Amount: 100, MaxInstallments: 3, FreeInstallments: 4}, "FreeInstallments is invalid. Value: 4"},
+		{InstallmentOptions{Amount: 100, MaxInstallments: 3, InterestRate: -1}, "InterestRate is invalid. Value: -0.01"},
+		{InstallmentOptions{Amount: 100, MaxInstallments: 3, InterestRate: 10001}, "InterestRate is invalid. Value: 100.01"},
+	}
+
+	for _, test := range tests {
+		_, err := CalculateInstallments(test.options)
+		assert.New(t).EqualError(err, test.err)
+	}
+}
+
+func TestParseInterestRate(t *testing.T) {
+	assertTest := assert.New(t)
+
+	rate, err := ParseInterestRate("1,99")
+	assertTest.Nil(err)
+	assertTest.Equal(InterestRate(199), rate)
+	assertTest.Equal("1.99", rate.String())
+
+	rate, _ = ParseInterestRate("13")
+	assertTest.Equal("13.00", rate.String())
+
+	rate, _ = ParseInterestRate(" 2,5% ")
+	assertTest.Equal(InterestRate(250), rate)
+
+	rate, err = ParseInterestRate("100")
+	assertTest.Nil(err)
+	assertTest.Equal(MAX_INTEREST_RATE, rate)
+
+	for _, value := range []string{"-1", "1.999", "1.000,50", "100.01", "1000", "1,", ".5", "abc", ""} {
+		_, err = ParseInterestRate(value)
+		assertTest.EqualError(err, "InterestRate is invalid. Value: "+value)
+	}
+}
+
+func TestClientCalculateInstallments(t *testing.T) {
+	var request *http.Request
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			io.WriteString(w, `{"installments": {
+				"2": {"installment": 2, "amount": 12600, "installment_amount": 6300},
+				"1": {"installment": 1, "amount": 10000, "installment_amount": 10000}
+			}}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	installments, err := client.CalculateInstallments(InstallmentOptions{
+		Amount:           NewMoney(100, 0),
+		MaxInstallments:  2,
+		FreeInstallments: 1,
+		InterestRate:     1300,
+	})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(PATH_INSTALLMENTS, request.URL.Path)
+	assertTest.Equal("10000", request.URL.Query().Get("amount"))
+	assertTest.Equal("2", request.URL.Query().Get("max_installments"))
+	assertTest.Equal("1", request.URL.Query().Get("free_installments"))
+	assertTest.Equal("13.00", request.URL.Query().Get("interest_rate"))
+	assertTest.Equal([]Installment{{1, 10000, 10000}, {2, 12600, 6300}}, installments)
+}
@@ -0,0 +1,110 @@
+package transactions
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DEFAULT_PUBLIC_KEY_TTL is how long a card_hash_key is reused before a new
+// one is requested.
+const DEFAULT_PUBLIC_KEY_TTL = 5 * time.Minute
+
+// PUBLIC_KEY_FETCH_TIMEOUT bounds the request of a new card_hash_key, which
+// is shared by every caller waiting for it and so can't use their contexts.
+const PUBLIC_KEY_FETCH_TIMEOUT = 30 * time.Second
+
+// keyCache keeps the public key used to create card hashes. A key is reused
+// until its ttl expires and, once it is in the last fifth of the ttl, a new
+// one is fetched in the background so charges rarely wait for it. Every
+// method is safe for concurrent use.
+type keyCache struct {
+	ttl    time.Duration
+	fetch  func(ctx context.Context) (PublicKey, error)
+	now    func() time.Time
+	logger Logger
+
+	mu        sync.Mutex
+	key       PublicKey
+	fetchedAt time.Time
+	valid     bool
+	inflight  *keyFetch
+}
+
+// keyFetch is a request for a new key, shared by the callers that need one
+// while it runs. done is closed once key and err are set.
+type keyFetch struct {
+	done chan struct{}
+	key  PublicKey
+	err  error
+}
+
+// get returns the cached key or waits for a new one. Concurrent callers
+// without a key share a single request, and each stops waiting as soon as
+// its own ctx is done.
+func (k *keyCache) get(ctx context.Context) (PublicKey, error) {
+	k.mu.Lock()
+	age := k.now().Sub(k.fetchedAt)
+	if k.valid && age < k.ttl {
+		if age >= k.ttl-k.ttl/5 {
+			k.start()
+		}
+		key := k.key
+		k.mu.Unlock()
+		return key, nil
+	}
+	fetch := k.start()
+	k.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.key, fetch.err
+	case <-ctx.Done():
+		return PublicKey{}, ctx.Err()
+	}
+}
+
+// start returns the request in progress or starts a new one. It must be
+// called with k.mu held.
+func (k *keyCache) start() *keyFetch {
+	if k.inflight == nil {
+		k.inflight = &keyFetch{done: make(chan struct{})}
+		go k.run(k.inflight)
+	}
+	return k.inflight
+}
+
+func (k *keyCache) run(fetch *keyFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), PUBLIC_KEY_FETCH_TIMEOUT)
+	defer cancel()
+	fetch.key, fetch.err = k.fetch(ctx)
+
+	k.mu.Lock()
+	k.inflight = nil
+	if fetch.err != nil {
+		k.logger.Warn("Error fetching the public key", "error", fetch.err.Error())
+	} else {
+		k.store(fetch.key)
+	}
+	k.mu.Unlock()
+
+	close(fetch.done)
+}
+
+// store must be called with k.mu held.
+func (k *keyCache) store(key PublicKey) {
+	k.key = key
+	k.fetchedAt = k.now()
+	k.valid = true
+}
+
+// invalidate drops the cached key when it is still stale, so callers that
+// saw the same rejected key fetch a new one only once.
+func (k *keyCache) invalidate(stale PublicKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.key.Id == stale.Id {
+		k.valid = false
+	}
+}
@@ -0,0 +1,229 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newKeyCache(now *time.Time, fetched *int32) *keyCache {
+	return &keyCache{
+		ttl: 5 * time.Minute,
+		fetch: func(ctx context.Context) (PublicKey, error) {
+			id := atomic.AddInt32(fetched, 1)
+			return PublicKey{Id: int(id)}, nil
+		},
+		now:    func() time.Time { return *now },
+		logger: nopLogger{},
+	}
+}
+
+func TestKeyCacheReuse(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetched := int32(0)
+	cache := newKeyCache(&now, &fetched)
+
+	first, _ := cache.get(context.Background())
+	now = now.Add(time.Minute)
+	second, _ := cache.get(context.Background())
+
+	assertTest := assert.New(t)
+	assertTest.Equal(1, first.Id)
+	assertTest.Equal(1, second.Id)
+	assertTest.Equal(int32(1), atomic.LoadInt32(&fetched))
+}
+
+func TestKeyCacheExpired(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetched := int32(0)
+	cache := newKeyCache(&now, &fetched)
+
+	cache.get(context.Background())
+	now = now.Add(5 * time.Minute)
+	key, _ := cache.get(context.Background())
+
+	assertTest := assert.New(t)
+	assertTest.Equal(2, key.Id)
+	assertTest.Equal(int32(2), atomic.LoadInt32(&fetched))
+}
+
+func TestKeyCacheRefreshBeforeExpiry(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	refreshed := make(chan struct{})
+	fetched := 0
+	cache := &keyCache{
+		ttl: 5 * time.Minute,
+		fetch: func(ctx context.Context) (PublicKey, error) {
+			fetched++
+			if fetched == 2 {
+				defer close(refreshed)
+			}
+			return PublicKey{Id: fetched}, nil
+		},
+		now:    func() time.Time { return now },
+		logger: nopLogger{},
+	}
+
+	cache.get(context.Background())
+	now = now.Add(4 * time.Minute)
+	key, _ := cache.get(context.Background())
+
+	assertTest := assert.New(t)
+	assertTest.Equal(1, key.Id)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("key not refreshed")
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	assertTest.Equal(2, cache.key.Id)
+	assertTest.Nil(cache.inflight)
+}
+
+func TestKeyCacheConcurrent(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetched := int32(0)
+	cache := newKeyCache(&now, &fetched)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.get(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+}
+
+func TestKeyCacheCancelledWhileFetching(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetched := int32(0)
+	cache := &keyCache{
+		ttl: 5 * time.Minute,
+		fetch: func(ctx context.Context) (PublicKey, error) {
+			atomic.AddInt32(&fetched, 1)
+			close(started)
+			<-release
+			return PublicKey{Id: 1}, nil
+		},
+		now:    func() time.Time { return now },
+		logger: nopLogger{},
+	}
+
+	waiting := make(chan PublicKey)
+	go func() {
+		key, _ := cache.get(context.Background())
+		waiting <- key
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.get(ctx)
+
+	assertTest := assert.New(t)
+	assertTest.ErrorIs(err, context.Canceled)
+
+	close(release)
+	assertTest.Equal(1, (<-waiting).Id)
+	assertTest.Equal(int32(1), atomic.LoadInt32(&fetched))
+}
+
+func TestKeyCacheFetchTimeout(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	var deadline time.Time
+	var ok bool
+	cache := &keyCache{
+		ttl: 5 * time.Minute,
+		fetch: func(ctx context.Context) (PublicKey, error) {
+			deadline, ok = ctx.Deadline()
+			return PublicKey{Id: 1}, nil
+		},
+		now:    func() time.Time { return now },
+		logger: nopLogger{},
+	}
+
+	cache.get(context.Background())
+
+	assertTest := assert.New(t)
+	assertTest.True(ok)
+	assertTest.True(time.Until(deadline) <= PUBLIC_KEY_FETCH_TIMEOUT)
+}
+
+func TestKeyCacheError(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	fail := true
+	cache := &keyCache{
+		ttl: 5 * time.Minute,
+		fetch: func(ctx context.Context) (PublicKey, error) {
+			if fail {
+				return PublicKey{}, errors.New("unavailable")
+			}
+			return PublicKey{Id: 1}, nil
+		},
+		now:    func() time.Time { return now },
+		logger: nopLogger{},
+	}
+
+	assertTest := assert.New(t)
+	_, err := cache.get(context.Background())
+	assertTest.EqualError(err, "unavailable")
+
+	fail = false
+	key, err := cache.get(context.Background())
+	assertTest.Nil(err)
+	assertTest.Equal(1, key.Id)
+}
+
+func TestKeyCacheInvalidate(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetched := int32(0)
+	cache := newKeyCache(&now, &fetched)
+
+	stale, _ := cache.get(context.Background())
+	cache.invalidate(stale)
+	fresh, _ := cache.get(context.Background())
+	cache.invalidate(stale)
+	again, _ := cache.get(context.Background())
+
+	assertTest := assert.New(t)
+	assertTest.Equal(2, fresh.Id)
+	assertTest.Equal(2, again.Id)
+	assertTest.Equal(int32(2), atomic.LoadInt32(&fetched))
+}
+
+func TestRecoverPublicKeyCached(t *testing.T) {
+	requests := int32(0)
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			io.WriteString(w, `{"id": 10, "public_key": "key", "ip": "127.0.0.1"}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithPublicKeyTTL(time.Hour))
+	client.RecoverPublicKey()
+	key, err := client.RecoverPublicKey()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(10, key.Id)
+	assertTest.Equal(int32(1), atomic.LoadInt32(&requests))
+}
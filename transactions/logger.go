@@ -0,0 +1,39 @@
+package transactions
+
+// Logger receives the logs of the client as a message followed by key value
+// pairs. A *slog.Logger can be given to WithLogger as is. Requests and
+// responses are logged at debug level after being redacted by RedactBody,
+// RedactQuery and RedactHeader.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger is the default Logger, which discards every log.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// NopLogger returns a Logger that discards every log.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// log returns the client logger, or a nopLogger for a client not created by
+// NewClient.
+func (c *client) log() Logger {
+	if c.logger == nil {
+		return nopLogger{}
+	}
+	return c.logger
+}
+
+func logging(logger Logger) bool {
+	_, off := logger.(nopLogger)
+	return !off
+}
@@ -0,0 +1,144 @@
+package transactions
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *recordLogger) record(level string, msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *recordLogger) Debug(msg string, args ...interface{}) { l.record("DEBUG", msg, args...) }
+func (l *recordLogger) Info(msg string, args ...interface{})  { l.record("INFO", msg, args...) }
+func (l *recordLogger) Warn(msg string, args ...interface{})  { l.record("WARN", msg, args...) }
+func (l *recordLogger) Error(msg string, args ...interface{}) { l.record("ERROR", msg, args...) }
+
+func (l *recordLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.logs, "\n")
+}
+
+func TestLoggerRedactsRequests(t *testing.T) {
+	var body string
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			io.WriteString(w, `{"id": 1, "status": "paid", "customer": {"documents": [{"type": "cpf", "number": "25185465026"}]}}`)
+		}),
+	)
+
+	defer server.Close()
+
+	logger := &recordLogger{}
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLogger(logger))
+
+	transaction := cardTransaction()
+	transaction.Amount = NewMoney(33, 0)
+	transaction.PaymentMethod = CREDIT_CARD.String()
+	transaction.Customer.Documents = []document{{CPF.String(), "25185465026"}}
+
+	for _, method := range []AuthenticationMethod{BODY, PARAM, BASIC_AUTH} {
+		_, err := client.Execute(transaction, method)
+		assert.New(t).Nil(err)
+	}
+
+	logs := logger.String()
+
+	assertTest := assert.New(t)
+	assertTest.Contains(body, "4111111111111111")
+	assertTest.Contains(logs, "Pagar.me request")
+	assertTest.Contains(logs, "Pagar.me response")
+	assertTest.Contains(logs, "411111******1111")
+	for _, secret := range []string{"ak_test_key", "4111111111111111", "25185465026", "card_cvv", "Authorization", "YWtfdGVzdF9rZXk6eA"} {
+		assertTest.NotContains(logs, secret)
+	}
+}
+
+func TestLoggerRedactsQueries(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"errors": [{"type": "invalid_parameter", "parameter_name": "customer", "message": "inválido"}]}`)
+		}),
+	)
+
+	defer server.Close()
+
+	logger := &recordLogger{}
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithLogger(logger))
+
+	it := client.ListTransactions(TransactionFilter{DocumentNumber: "25185465026"})
+
+	assertTest := assert.New(t)
+	assertTest.False(it.Next())
+	apiError, ok := it.Err().(*APIError)
+	assertTest.True(ok)
+	assertTest.Contains(apiError.Path, "/transactions?")
+	assertTest.NotContains(apiError.Path, "25185465026")
+	assertTest.NotContains(logger.String(), "25185465026")
+
+	accounts := client.ListBankAccounts("25185465026")
+	assertTest.False(accounts.Next())
+	assertTest.NotContains(accounts.Err().Error(), "25185465026")
+	assertTest.NotContains(logger.String(), "25185465026")
+}
+
+func TestLoggerRedactsURLErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	logger := &recordLogger{}
+	policy := RetryPolicy{MaxAttempts: 2}
+	client := NewClient(WithAPIKey("ak_live_SECRET"), WithBaseURL(server.URL), WithRetryPolicy(policy), WithLogger(logger))
+
+	_, err := client.RecoverPublicKey()
+	assertTest := assert.New(t)
+	assertTest.NotNil(err)
+	assertTest.NotContains(err.Error(), "ak_live_SECRET")
+
+	_, err = client.Execute(Transaction{Amount: 100}, PARAM)
+	assertTest.NotNil(err)
+	assertTest.NotContains(err.Error(), "ak_live_SECRET")
+
+	client = NewClient(WithAPIKey("ak_live_SECRET"), WithEncryptionKey("ek_live_SECRET"), WithBaseURL(server.URL), WithRetryPolicy(policy), WithLogger(logger))
+	_, err = client.RecoverPublicKey()
+	assertTest.NotNil(err)
+	assertTest.Contains(err.Error(), PATH_HASH)
+	assertTest.NotContains(err.Error(), "ek_live_SECRET")
+
+	assertTest.Contains(logger.String(), "Retrying after error")
+	assertTest.NotContains(logger.String(), "SECRET")
+}
+
+func TestLoggerOffByDefault(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.False(logging(NewClient().log()))
+	assertTest.False(logging((&client{}).log()))
+}
+
+func TestSlogLogger(t *testing.T) {
+	var out strings.Builder
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := NewClient(WithLogger(logger))
+	client.log().Warn("Retrying after status", "status", 503)
+
+	assert.New(t).Contains(out.String(), "level=WARN msg=\"Retrying after status\" status=503")
+}
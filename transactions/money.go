@@ -0,0 +1,228 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in centavos, the unit of every Pagar.me amount field.
+// It is encoded in JSON as the integer number of centavos.
+type Money int64
+
+// NewMoney returns reais and centavos as Money, such as NewMoney(33, 50)
+// for R$ 33,50.
+func NewMoney(reais int64, centavos int64) Money {
+	return Money(reais*100 + centavos)
+}
+
+// ParseMoney accepts amounts in Brazilian ("1.234,56") or international
+// ("1,234.56" or "1234.56") notation, optionally prefixed by "R$" and a
+// minus sign. A separator followed by one or two digits is the decimal
+// separator; one followed by three digits groups thousands. More than two
+// decimal digits is an error instead of being rounded.
+func ParseMoney(value string) (Money, error) {
+	s := strings.TrimSpace(value)
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimSpace(strings.TrimPrefix(s, "-"))
+	s = strings.TrimSpace(strings.TrimPrefix(s, "R$"))
+
+	if !negative && strings.HasPrefix(s, "-") {
+		negative = true
+		s = strings.TrimSpace(strings.TrimPrefix(s, "-"))
+	}
+
+	integer, decimals := s, ""
+	if i := strings.LastIndexAny(s, ".,"); i >= 0 && len(s)-i-1 <= 2 {
+		integer, decimals = s[:i], s[i+1:]
+		if decimals == "" || strings.ContainsAny(integer, string(s[i])) {
+			return 0, &InvalidValueError{"Money", value}
+		}
+	}
+
+	integer, ok := ungroup(integer)
+	if !ok || !isDigits(decimals) {
+		return 0, &InvalidValueError{"Money", value}
+	}
+
+	reais, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || reais > (math.MaxInt64-99)/100 {
+		return 0, &InvalidValueError{"Money", value}
+	}
+
+	centavos, _ := strconv.ParseInt((decimals + "00")[:2], 10, 64)
+
+	amount := NewMoney(reais, centavos)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// ungroup removes the thousands separators of the integer part, which must
+// all be the same and separate groups of three digits. The first group can't
+// start with zero, so "0.005" is refused instead of read as 5 reais.
+func ungroup(integer string) (string, bool) {
+	if integer == "" {
+		return "", false
+	}
+
+	separator := strings.IndexAny(integer, ".,")
+	if separator < 0 {
+		return integer, isDigits(integer)
+	}
+
+	groups := strings.Split(integer, string(integer[separator]))
+	if len(groups[0]) == 0 || len(groups[0]) > 3 || groups[0][0] == '0' {
+		return "", false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", false
+		}
+	}
+
+	joined := strings.Join(groups, "")
+	return joined, isDigits(joined)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Reais is the integer part of the amount.
+func (m Money) Reais() int64 {
+	return int64(m) / 100
+}
+
+// Centavos is the fractional part of the amount, negative for negative
+// amounts.
+func (m Money) Centavos() int64 {
+	return int64(m) % 100
+}
+
+// String formats the amount in BRL, such as "R$ 1.234,56".
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	reais := strconv.FormatInt(cents/100, 10)
+	for i := len(reais) - 3; i > 0; i -= 3 {
+		reais = reais[:i] + "." + reais[i:]
+	}
+
+	return fmt.Sprintf("%vR$ %v,%02d", sign, reais, cents%100)
+}
+
+// Decimal formats the amount with a dot and two decimals, such as "1234.56".
+func (m Money) Decimal() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%v%v.%02d", sign, cents/100, cents%100)
+}
+
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+func (m Money) Mul(quantity int64) Money {
+	return m * Money(quantity)
+}
+
+// Split divides the amount in n parts that sum exactly to it. The remainder
+// centavos go one each to the first parts.
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	parts, _ := m.Allocate(ratios...)
+	return parts
+}
+
+// Allocate divides the amount proportionally to ratios, such as
+// Allocate(70, 30), in parts that sum exactly to it. The remainder centavos
+// go one each to the first parts with a non zero ratio.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	total := int64(0)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("ratios must not be negative")
+		}
+		if ratio > math.MaxInt64-total {
+			return nil, errors.New("ratios sum overflows")
+		}
+		total += ratio
+	}
+
+	if total == 0 {
+		return nil, errors.New("ratios must not all be zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m
+	for i, ratio := range ratios {
+		parts[i] = m.mulDiv(ratio, total)
+		remainder -= parts[i]
+	}
+
+	step := Money(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] > 0 {
+			parts[i] += step
+			remainder -= step
+		}
+	}
+
+	return parts, nil
+}
+
+// mulDiv returns m * numerator / denominator truncated towards zero, with
+// the intermediate product computed by math/big as in roundDiv.
+func (m Money) mulDiv(numerator int64, denominator int64) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(numerator))
+	return Money(product.Quo(product, big.NewInt(denominator)).Int64())
+}
+
+// Set parses value with ParseMoney, so *Money can be used as a command line
+// flag value.
+func (m *Money) Set(value string) error {
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m *Money) Type() string {
+	return "money"
+}
@@ -0,0 +1,123 @@
+package transactions
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value string
+		money Money
+	}{
+		{"1234.56", 123456},
+		{"1234,56", 123456},
+		{"1.234,56", 123456},
+		{"1,234.56", 123456},
+		{"R$ 1.234,56", 123456},
+		{"1.234.567,89", 123456789},
+		{"1.234", 123400},
+		{"33", 3300},
+		{"33.5", 3350},
+		{"0,01", 1},
+		{"-10,50", -1050},
+		{"R$ -10,50", -1050},
+		{"92233720368547757.00", 9223372036854775700},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			money, err := ParseMoney(test.value)
+
+			assertTest := assert.New(t)
+			assertTest.Nil(err)
+			assertTest.Equal(test.money, money)
+		})
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	for _, value := range []string{"", "0.005", "1.234.56", "1,23,456", "12.34.567", "1.2345", "abc", "1,", "R$", "--1", "92233720368547758.00"} {
+		_, err := ParseMoney(value)
+
+		assertTest := assert.New(t)
+		assertTest.EqualError(err, "Money is invalid. Value: "+value)
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal("R$ 1.234,56", Money(123456).String())
+	assertTest.Equal("R$ 0,05", Money(5).String())
+	assertTest.Equal("-R$ 1.000.000,00", Money(-100000000).String())
+	assertTest.Equal("1234.56", Money(123456).Decimal())
+	assertTest.Equal("-0.05", Money(-5).Decimal())
+	assertTest.Equal(int64(1234), Money(123456).Reais())
+	assertTest.Equal(int64(56), Money(123456).Centavos())
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal(Money(1500), NewMoney(10, 0).Add(NewMoney(5, 0)))
+	assertTest.Equal(Money(-500), NewMoney(10, 0).Sub(NewMoney(15, 0)))
+	assertTest.Equal(Money(2997), NewMoney(9, 99).Mul(3))
+}
+
+func TestMoneySplit(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.Equal([]Money{34, 33, 33}, Money(100).Split(3))
+	assertTest.Equal([]Money{-34, -33, -33}, Money(-100).Split(3))
+	assertTest.Nil(Money(100).Split(0))
+}
+
+func TestMoneyAllocate(t *testing.T) {
+	parts, err := Money(1001).Allocate(70, 30)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Money{701, 300}, parts)
+
+	parts, _ = Money(10).Allocate(0, 1, 1, 1)
+	assertTest.Equal([]Money{0, 4, 3, 3}, parts)
+
+	_, err = Money(10).Allocate(0, 0)
+	assertTest.EqualError(err, "ratios must not all be zero")
+
+	_, err = Money(10).Allocate(1, -1)
+	assertTest.EqualError(err, "ratios must not be negative")
+
+	_, err = Money(10).Allocate(math.MaxInt64, 1)
+	assertTest.EqualError(err, "ratios sum overflows")
+}
+
+func TestMoneyAllocateLargeRatios(t *testing.T) {
+	parts, err := Money(99999999).Allocate(4e12, 6e12)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Money{40000000, 59999999}, parts)
+
+	parts, _ = Money(math.MaxInt64).Allocate(math.MaxInt64/2, math.MaxInt64/2)
+	assertTest.Equal([]Money{math.MaxInt64/2 + 1, math.MaxInt64 / 2}, parts)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, _ := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{NewMoney(33, 50)})
+
+	assertTest := assert.New(t)
+	assertTest.Equal(`{"amount":3350}`, string(data))
+}
+
+func TestMoneySet(t *testing.T) {
+	var money Money
+
+	assertTest := assert.New(t)
+	assertTest.Nil(money.Set("1.234,56"))
+	assertTest.Equal(Money(123456), money)
+	assertTest.NotNil(money.Set("1.2345"))
+	assertTest.Equal("money", money.Type())
+}
@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const PATH_CAPTURE = "/transactions/%v/capture"
+const PATH_REFUND = "/transactions/%v/refund"
+
+// BankAccount receives the money of boleto refunds and the transfers of
+// recipients. ID, DocumentType and DateCreated are set by Pagar.me.
+type BankAccount struct {
+	Object         string     `json:"object,omitempty"`
+	ID             int        `json:"id,omitempty"`
+	BankCode       string     `json:"bank_code"`
+	Agencia        string     `json:"agencia"`
+	AgenciaDv      string     `json:"agencia_dv,omitempty"`
+	Conta          string     `json:"conta"`
+	ContaDv        string     `json:"conta_dv"`
+	Type           string     `json:"type,omitempty"`
+	DocumentType   string     `json:"document_type,omitempty"`
+	DocumentNumber string     `json:"document_number"`
+	LegalName      string     `json:"legal_name"`
+	DateCreated    *time.Time `json:"date_created,omitempty"`
+}
+
+type operationRequest struct {
+	Amount      Money        `json:"amount,omitempty"`
+	BankAccount *BankAccount `json:"bank_account,omitempty"`
+}
+
+// Capture captures a transaction created with Capture(false). An amount of
+// zero captures the whole authorized amount, a negative one is refused.
+func (c *client) Capture(id int, amount Money) (*TransactionResponse, error) {
+	return c.CaptureContext(context.Background(), id, amount)
+}
+
+func (c *client) CaptureContext(ctx context.Context, id int, amount Money) (*TransactionResponse, error) {
+	if amount < 0 {
+		return nil, &InvalidValueError{"Amount", amount.Decimal()}
+	}
+
+	result := TransactionResponse{}
+	payload := operationRequest{Amount: amount}
+
+	if err := c.request(ctx, "POST", fmt.Sprintf(PATH_CAPTURE, id), payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Refund refunds a credit card transaction. An amount of zero refunds the
+// whole paid amount, a positive one makes a partial refund and a negative
+// one is refused.
+func (c *client) Refund(id int, amount Money) (*TransactionResponse, error) {
+	return c.RefundContext(context.Background(), id, amount)
+}
+
+func (c *client) RefundContext(ctx context.Context, id int, amount Money) (*TransactionResponse, error) {
+	if amount < 0 {
+		return nil, &InvalidValueError{"Amount", amount.Decimal()}
+	}
+
+	return c.refund(ctx, id, operationRequest{Amount: amount})
+}
+
+// RefundBoleto refunds a paid boleto, transferring the money to account,
+// which is checked and normalized by ValidateBankAccount.
+func (c *client) RefundBoleto(id int, amount Money, account BankAccount) (*TransactionResponse, error) {
+	return c.RefundBoletoContext(context.Background(), id, amount, account)
+}
+
+func (c *client) RefundBoletoContext(ctx context.Context, id int, amount Money, account BankAccount) (*TransactionResponse, error) {
+
+	if amount < 0 {
+		return nil, &InvalidValueError{"Amount", amount.Decimal()}
+	}
+
+	account, err := ValidateBankAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.refund(ctx, id, operationRequest{Amount: amount, BankAccount: &account})
+}
+
+func (c *client) refund(ctx context.Context, id int, payload operationRequest) (*TransactionResponse, error) {
+	result := TransactionResponse{}
+
+	if err := c.request(ctx, "POST", fmt.Sprintf(PATH_REFUND, id), payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
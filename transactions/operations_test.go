@@ -0,0 +1,219 @@
+package transactions
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newOperationServer(status int, response string, requests *[]*http.Request, bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			*requests = append(*requests, r)
+			*bodies = append(*bodies, body)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, response)
+		}),
+	)
+}
+
+func TestCapture(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "paid", "amount": 1050}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.Capture(1234, NewMoney(10, 50))
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(1234, result.ID)
+	assertTest.Equal(PAID, result.Status)
+	assertTest.Equal(http.MethodPost, requests[0].Method)
+	assertTest.Equal("/transactions/1234/capture", requests[0].URL.Path)
+	assertTest.Equal(float64(1050), bodies[0]["amount"])
+
+	user, _, _ := requests[0].BasicAuth()
+	assertTest.Equal("ak_test_key", user)
+}
+
+func TestCaptureFullAmount(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "paid"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	client.Capture(1234, 0)
+
+	assertTest := assert.New(t)
+	assertTest.Empty(bodies[0])
+}
+
+func TestCaptureError(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(400, `{"errors":[{"type":"invalid_parameter","parameter_name":"amount","message":"Valor acima do autorizado"}]}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.Capture(1234, NewMoney(100, 0))
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "Pagar.me error. Status: 400 Path: /transactions/1234/capture. Errors: amount: Valor acima do autorizado")
+}
+
+func TestCaptureNegativeAmount(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "paid"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.Capture(1234, NewMoney(-10, 0))
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "Amount is invalid. Value: -10.00")
+	assertTest.Empty(requests)
+}
+
+func TestRefundPartial(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "paid", "refunded_amount": 500}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.Refund(1234, NewMoney(5, 0))
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(1234, result.ID)
+	assertTest.Equal("/transactions/1234/refund", requests[0].URL.Path)
+	assertTest.Equal(float64(500), bodies[0]["amount"])
+	assertTest.Nil(bodies[0]["bank_account"])
+}
+
+func TestRefundNegativeAmount(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "refunded"}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.Refund(1234, Money(-1))
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "Amount is invalid. Value: -0.01")
+	assertTest.Empty(requests)
+}
+
+func TestRefundIsNotRetried(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(503, ``, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	_, err := client.Refund(1234, NewMoney(5, 0))
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Pagar.me error. Status: 503 Path: /transactions/1234/refund")
+	assertTest.Len(requests, 1)
+}
+
+func TestRefundBoleto(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "pending_refund"}`, &requests, &bodies)
+	defer server.Close()
+
+	account := BankAccount{
+		BankCode:       "341",
+		Agencia:        "0057",
+		Conta:          "72192",
+		ContaDv:        "0",
+		DocumentNumber: "251.854.650-26",
+		LegalName:      "Leandro Greijal",
+	}
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.RefundBoleto(1234, 0, account)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(PENDING_REFUND, result.Status)
+	assertTest.Nil(bodies[0]["amount"])
+	assertTest.Equal(map[string]interface{}{
+		"bank_code":       "341",
+		"agencia":         "0057",
+		"conta":           "72192",
+		"conta_dv":        "0",
+		"document_number": "25185465026",
+		"legal_name":      "Leandro Greijal",
+	}, bodies[0]["bank_account"])
+}
+
+func TestRefundBoletoInvalidAccount(t *testing.T) {
+	client := NewClient(WithBaseURL("http://localhost:0"))
+	_, err := client.RefundBoleto(1234, 0, BankAccount{BankCode: "341", Agencia: "0057"})
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "BankAccount.Conta is invalid. Value: ")
+}
+
+func TestRefundBoletoInvalidCheckDigit(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "pending_refund"}`, &requests, &bodies)
+	defer server.Close()
+
+	account := BankAccount{BankCode: "341", Agencia: "0057", Conta: "72192", ContaDv: "1", DocumentNumber: "25185465026", LegalName: "Leandro Greijal"}
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.RefundBoleto(1234, 0, account)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "BankAccount.ContaDv is invalid. Value: 1")
+	assertTest.Empty(requests)
+}
+
+func TestRefundBoletoNegativeAmount(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"id": 1234, "status": "pending_refund"}`, &requests, &bodies)
+	defer server.Close()
+
+	account := BankAccount{BankCode: "341", Agencia: "0057", Conta: "72192", ContaDv: "0", DocumentNumber: "25185465026", LegalName: "Leandro Greijal"}
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.RefundBoleto(1234, NewMoney(-5, 0), account)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "Amount is invalid. Value: -5.00")
+	assertTest.Empty(requests)
+}
+
+func TestBuilderCapture(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Capture(false)
+	transactionTest := tb.transaction
+	json, _ := transactionTest.marshal()
+
+	assertTest := assert.New(t)
+	assertTest.False(*transactionTest.Capture)
+	assertTest.Contains(string(json), `"capture":false`)
+}
@@ -0,0 +1,85 @@
+package transactions
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures the client created by NewClient.
+type Option func(*client)
+
+// WithAPIKey sets the api_key (ak_test_... or ak_live_...) used to
+// authenticate every request.
+func WithAPIKey(key string) Option {
+	return func(c *client) {
+		c.apiKey = key
+	}
+}
+
+// WithEncryptionKey sets the encryption_key (ek_test_... or ek_live_...) used
+// to recover the public key for card hashes. The api_key is used when empty.
+func WithEncryptionKey(key string) Option {
+	return func(c *client) {
+		c.encryptionKey = key
+	}
+}
+
+func WithBaseURL(url string) Option {
+	return func(c *client) {
+		c.url = url
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.Client = httpClient
+	}
+}
+
+// WithTimeout limits each HTTP request. It is applied over a copy of the
+// HTTP client, so a client given to WithHTTPClient is never modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *client) {
+		c.userAgent = userAgent
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithPollInterval sets the first and the maximum interval between the
+// requests made by WaitForStatus.
+func WithPollInterval(interval time.Duration, max time.Duration) Option {
+	return func(c *client) {
+		c.pollInterval = interval
+		c.maxPollInterval = max
+	}
+}
+
+// WithPublicKeyTTL sets how long the card_hash_key is reused, which is
+// DEFAULT_PUBLIC_KEY_TTL by default.
+func WithPublicKeyTTL(ttl time.Duration) Option {
+	return func(c *client) {
+		c.publicKeyTTL = ttl
+	}
+}
+
+// WithLogger sends the client logs to logger, such as a *slog.Logger.
+// Nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(c *client) {
+		if logger == nil {
+			logger = nopLogger{}
+		}
+		c.logger = logger
+	}
+}
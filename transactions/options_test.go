@@ -0,0 +1,131 @@
+package transactions
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient()
+
+	assertTest := assert.New(t)
+	assertTest.Equal(BASE_URL, client.url)
+	assertTest.Equal(USER_AGENT, client.userAgent)
+	assertTest.Equal(DefaultRetryPolicy, client.retryPolicy)
+	assertTest.Empty(client.apiKey)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	client := NewClient(
+		WithAPIKey("ak_test_key"),
+		WithEncryptionKey("ek_test_key"),
+		WithBaseURL("http://localhost"),
+		WithHTTPClient(httpClient),
+		WithTimeout(3*time.Second),
+		WithUserAgent("loja/1.0"),
+		WithRetryPolicy(NoRetry),
+	)
+
+	assertTest := assert.New(t)
+	assertTest.Equal("ak_test_key", client.apiKey)
+	assertTest.Equal("ek_test_key", client.encryptionKey)
+	assertTest.Equal("http://localhost", client.url)
+	assertTest.Equal("loja/1.0", client.userAgent)
+	assertTest.Equal(NoRetry, client.retryPolicy)
+	assertTest.Equal(3*time.Second, client.Timeout)
+	assertTest.Equal(time.Duration(0), httpClient.Timeout)
+}
+
+func TestExecuteAuthenticationMethods(t *testing.T) {
+
+	var request *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			io.WriteString(w, "{}")
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithUserAgent("loja/1.0"))
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	assertTest := assert.New(t)
+
+	client.Execute(transaction, BODY)
+	assertTest.Equal("ak_test_key", body["api_key"])
+	assertTest.Equal("loja/1.0", request.Header.Get("User-Agent"))
+
+	client.Execute(transaction, PARAM)
+	assertTest.Equal("ak_test_key", request.URL.Query().Get("api_key"))
+	assertTest.Nil(body["api_key"])
+
+	client.Execute(transaction, BASIC_AUTH)
+	user, password, ok := request.BasicAuth()
+	assertTest.True(ok)
+	assertTest.Equal("ak_test_key", user)
+	assertTest.Equal("x", password)
+	assertTest.Nil(body["api_key"])
+}
+
+func TestRecoverPublicKeyBasicAuth(t *testing.T) {
+
+	var request *http.Request
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			io.WriteString(w, `{"id": 10, "public_key": "key", "ip": "127.0.0.1"}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	_, err := client.RecoverPublicKey()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Empty(request.URL.RawQuery)
+	user, _, ok := request.BasicAuth()
+	assertTest.True(ok)
+	assertTest.Equal("ak_test_key", user)
+}
+
+func TestRecoverPublicKeyEncryptionKey(t *testing.T) {
+
+	var request *http.Request
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			io.WriteString(w, `{"id": 10, "public_key": "key", "ip": "127.0.0.1"}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithEncryptionKey("ek_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	key, err := client.RecoverPublicKey()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(PATH_HASH, request.URL.Path)
+	assertTest.Equal("ek_test_key", request.URL.Query().Get("encryption_key"))
+	assertTest.Empty(request.URL.Query().Get("api_key"))
+	_, _, basicAuth := request.BasicAuth()
+	assertTest.False(basicAuth)
+	assertTest.Equal(10, key.Id)
+}
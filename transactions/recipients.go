@@ -0,0 +1,261 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const PATH_RECIPIENTS = "/recipients"
+const PATH_RECIPIENT_ID = "/recipients/%v"
+
+// TransferInterval is how often the balance of a recipient is transferred to
+// its bank account.
+type TransferInterval string
+
+const (
+	DAILY   TransferInterval = "daily"
+	WEEKLY  TransferInterval = "weekly"
+	MONTHLY TransferInterval = "monthly"
+)
+
+// AnticipationType chooses which receivables are anticipated automatically.
+type AnticipationType string
+
+const (
+	// FULL anticipates every receivable.
+	FULL AnticipationType = "full"
+	// ANTICIPATION_1025 anticipates on the AutomaticAnticipationDays of the
+	// month.
+	ANTICIPATION_1025 AnticipationType = "1025"
+)
+
+// TransferSettings are the automatic transfers of a recipient. TransferDay
+// is 0 for DAILY, a weekday from 1 (monday) to 5 for WEEKLY and a day of the
+// month from 1 to 31 for MONTHLY.
+type TransferSettings struct {
+	TransferEnabled  bool             `json:"transfer_enabled"`
+	TransferInterval TransferInterval `json:"transfer_interval,omitempty"`
+	TransferDay      int              `json:"transfer_day"`
+}
+
+// AnticipationSettings are the automatic anticipations of a recipient.
+// AnticipatableVolumePercentage, from 0 to 100, is the part of the
+// receivables that can be anticipated.
+type AnticipationSettings struct {
+	AutomaticAnticipationEnabled  bool             `json:"automatic_anticipation_enabled"`
+	AutomaticAnticipationType     AnticipationType `json:"automatic_anticipation_type,omitempty"`
+	AutomaticAnticipationDays     []int            `json:"automatic_anticipation_days,omitempty"`
+	AnticipatableVolumePercentage int              `json:"anticipatable_volume_percentage"`
+}
+
+// Recipient is the request body of CreateRecipient. It receives its
+// transfers in a new BankAccount or in the existing BankAccountID.
+type Recipient struct {
+	BankAccountID int          `json:"bank_account_id,omitempty"`
+	BankAccount   *BankAccount `json:"bank_account,omitempty"`
+	TransferSettings
+	AnticipationSettings
+	PostbackURL string            `json:"postback_url,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// bankAccountRequest is the request body of UpdateRecipientBankAccount.
+type bankAccountRequest struct {
+	BankAccountID int          `json:"bank_account_id,omitempty"`
+	BankAccount   *BankAccount `json:"bank_account,omitempty"`
+}
+
+func (s TransferSettings) validate() error {
+	switch {
+	case !s.TransferEnabled && s.TransferInterval == "":
+		return nil
+	case s.TransferInterval == DAILY && s.TransferDay == 0,
+		s.TransferInterval == WEEKLY && s.TransferDay >= 1 && s.TransferDay <= 5,
+		s.TransferInterval == MONTHLY && s.TransferDay >= 1 && s.TransferDay <= 31:
+		return nil
+	case s.TransferInterval != DAILY && s.TransferInterval != WEEKLY && s.TransferInterval != MONTHLY:
+		return &InvalidValueError{"TransferInterval", string(s.TransferInterval)}
+	}
+	return &InvalidValueError{"TransferDay", strconv.Itoa(s.TransferDay)}
+}
+
+func (s AnticipationSettings) validate() error {
+	switch s.AutomaticAnticipationType {
+	case "", FULL:
+		if len(s.AutomaticAnticipationDays) > 0 {
+			return &InvalidValueError{"AutomaticAnticipationDays", fmt.Sprint(s.AutomaticAnticipationDays)}
+		}
+	case ANTICIPATION_1025:
+		for _, day := range s.AutomaticAnticipationDays {
+			if day < 1 || day > 31 {
+				return &InvalidValueError{"AutomaticAnticipationDays", fmt.Sprint(s.AutomaticAnticipationDays)}
+			}
+		}
+	default:
+		return &InvalidValueError{"AutomaticAnticipationType", string(s.AutomaticAnticipationType)}
+	}
+
+	if s.AnticipatableVolumePercentage < 0 || s.AnticipatableVolumePercentage > 100 {
+		return &InvalidValueError{"AnticipatableVolumePercentage", strconv.Itoa(s.AnticipatableVolumePercentage)}
+	}
+	return nil
+}
+
+// validBankAccount checks that exactly one of account and id is given and
+// validates account with ValidateBankAccount.
+func validBankAccount(account *BankAccount, id int) (*BankAccount, error) {
+	switch {
+	case account == nil && id <= 0:
+		return nil, &MissingValueError{"BankAccount"}
+	case account != nil && id != 0:
+		return nil, &InvalidValueError{"BankAccountID", strconv.Itoa(id)}
+	case account == nil:
+		return nil, nil
+	}
+
+	valid, err := ValidateBankAccount(*account)
+	if err != nil {
+		return nil, err
+	}
+	return &valid, nil
+}
+
+// CreateRecipient registers a recipient of split rules.
+func (c *client) CreateRecipient(recipient Recipient) (*RecipientResponse, error) {
+	return c.CreateRecipientContext(context.Background(), recipient)
+}
+
+func (c *client) CreateRecipientContext(ctx context.Context, recipient Recipient) (*RecipientResponse, error) {
+	account, err := validBankAccount(recipient.BankAccount, recipient.BankAccountID)
+	if err != nil {
+		return nil, err
+	}
+	recipient.BankAccount = account
+
+	if err := recipient.TransferSettings.validate(); err != nil {
+		return nil, err
+	}
+	if err := recipient.AnticipationSettings.validate(); err != nil {
+		return nil, err
+	}
+
+	return c.recipientRequest(ctx, "POST", PATH_RECIPIENTS, recipient)
+}
+
+func (c *client) GetRecipient(id string) (*RecipientResponse, error) {
+	return c.GetRecipientContext(context.Background(), id)
+}
+
+func (c *client) GetRecipientContext(ctx context.Context, id string) (*RecipientResponse, error) {
+	return c.recipientRequest(ctx, "GET", fmt.Sprintf(PATH_RECIPIENT_ID, url.PathEscape(id)), nil)
+}
+
+// UpdateTransferSettings replaces the automatic transfers of a recipient.
+func (c *client) UpdateTransferSettings(id string, settings TransferSettings) (*RecipientResponse, error) {
+	return c.UpdateTransferSettingsContext(context.Background(), id, settings)
+}
+
+func (c *client) UpdateTransferSettingsContext(ctx context.Context, id string, settings TransferSettings) (*RecipientResponse, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return c.recipientRequest(ctx, "PUT", fmt.Sprintf(PATH_RECIPIENT_ID, url.PathEscape(id)), settings)
+}
+
+// UpdateAnticipationSettings replaces the automatic anticipations of a
+// recipient.
+func (c *client) UpdateAnticipationSettings(id string, settings AnticipationSettings) (*RecipientResponse, error) {
+	return c.UpdateAnticipationSettingsContext(context.Background(), id, settings)
+}
+
+func (c *client) UpdateAnticipationSettingsContext(ctx context.Context, id string, settings AnticipationSettings) (*RecipientResponse, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+	return c.recipientRequest(ctx, "PUT", fmt.Sprintf(PATH_RECIPIENT_ID, url.PathEscape(id)), settings)
+}
+
+// UpdateRecipientBankAccount moves the transfers of a recipient to a new
+// account or to the existing account accountID. Pagar.me only accepts an
+// account of the same owner, so the document of the account is checked
+// against the current one of the recipient before the change.
+func (c *client) UpdateRecipientBankAccount(id string, account *BankAccount, accountID int) (*RecipientResponse, error) {
+	return c.UpdateRecipientBankAccountContext(context.Background(), id, account, accountID)
+}
+
+func (c *client) UpdateRecipientBankAccountContext(ctx context.Context, id string, account *BankAccount, accountID int) (*RecipientResponse, error) {
+	account, err := validBankAccount(account, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, err := c.GetRecipientContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	document := ""
+	if account != nil {
+		document = account.DocumentNumber
+	} else {
+		existing, err := c.GetBankAccountContext(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		document = existing.DocumentNumber
+	}
+
+	if !sameDocument(document, recipient.BankAccount.DocumentNumber) {
+		return nil, &DocumentOwnershipError{RecipientID: id, Document: document}
+	}
+
+	payload := bankAccountRequest{BankAccountID: accountID, BankAccount: account}
+	return c.recipientRequest(ctx, "PUT", fmt.Sprintf(PATH_RECIPIENT_ID, url.PathEscape(id)), payload)
+}
+
+func (c *client) recipientRequest(ctx context.Context, method string, path string, payload interface{}) (*RecipientResponse, error) {
+	result := RecipientResponse{}
+
+	if err := c.request(ctx, method, path, payload, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// RecipientIterator walks the result of ListRecipients like
+// TransactionIterator.
+type RecipientIterator struct {
+	pager
+	buffer  []RecipientResponse
+	current RecipientResponse
+}
+
+func (it *RecipientIterator) Next() bool {
+	if len(it.buffer) == 0 && !it.next(&it.buffer) {
+		return false
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+func (it *RecipientIterator) Recipient() RecipientResponse {
+	return it.current
+}
+
+func (it *RecipientIterator) Err() error {
+	return it.err
+}
+
+// ListRecipients returns an iterator over every recipient. No request is
+// made until Next is called.
+func (c *client) ListRecipients() *RecipientIterator {
+	return c.ListRecipientsContext(context.Background())
+}
+
+func (c *client) ListRecipientsContext(ctx context.Context) *RecipientIterator {
+	return &RecipientIterator{pager: pager{ctx: ctx, client: c, path: PATH_RECIPIENTS}}
+}
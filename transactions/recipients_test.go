@@ -0,0 +1,228 @@
+package transactions
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransferSettingsValidate(t *testing.T) {
+	tests := []struct {
+		settings TransferSettings
+		err      string
+	}{
+		{TransferSettings{}, ""},
+		{TransferSettings{TransferEnabled: true, TransferInterval: DAILY}, ""},
+		{TransferSettings{TransferEnabled: true, TransferInterval: WEEKLY, TransferDay: 5}, ""},
+		{TransferSettings{TransferEnabled: true, TransferInterval: MONTHLY, TransferDay: 31}, ""},
+		{TransferSettings{TransferEnabled: true}, "TransferInterval is invalid. Value: "},
+		{TransferSettings{TransferEnabled: true, TransferInterval: "yearly", TransferDay: 1}, "TransferInterval is invalid. Value: yearly"},
+		{TransferSettings{TransferEnabled: true, TransferInterval: DAILY, TransferDay: 1}, "TransferDay is invalid. Value: 1"},
+		{TransferSettings{TransferEnabled: true, TransferInterval: WEEKLY, TransferDay: 6}, "TransferDay is invalid. Value: 6"},
+		{TransferSettings{TransferEnabled: true, TransferInterval: MONTHLY, TransferDay: 0}, "TransferDay is invalid. Value: 0"},
+	}
+
+	for _, test := range tests {
+		err := test.settings.validate()
+		if test.err == "" {
+			assert.New(t).Nil(err)
+		} else {
+			assert.New(t).EqualError(err, test.err)
+		}
+	}
+}
+
+func TestAnticipationSettingsValidate(t *testing.T) {
+	tests := []struct {
+		settings AnticipationSettings
+		err      string
+	}{
+		{AnticipationSettings{}, ""},
+		{AnticipationSettings{AutomaticAnticipationEnabled: true, AutomaticAnticipationType: FULL, AnticipatableVolumePercentage: 100}, ""},
+		{AnticipationSettings{AutomaticAnticipationEnabled: true, AutomaticAnticipationType: ANTICIPATION_1025, AutomaticAnticipationDays: []int{10, 25}}, ""},
+		{AnticipationSettings{AutomaticAnticipationType: FULL, AutomaticAnticipationDays: []int{10}}, "AutomaticAnticipationDays is invalid. Value: [10]"},
+		{AnticipationSettings{AutomaticAnticipationType: ANTICIPATION_1025, AutomaticAnticipationDays: []int{10, 32}}, "AutomaticAnticipationDays is invalid. Value: [10 32]"},
+		{AnticipationSettings{AutomaticAnticipationType: "partial"}, "AutomaticAnticipationType is invalid. Value: partial"},
+		{AnticipationSettings{AnticipatableVolumePercentage: 101}, "AnticipatableVolumePercentage is invalid. Value: 101"},
+	}
+
+	for _, test := range tests {
+		err := test.settings.validate()
+		if test.err == "" {
+			assert.New(t).Nil(err)
+		} else {
+			assert.New(t).EqualError(err, test.err)
+		}
+	}
+}
+
+func TestCreateRecipient(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "recipient", "id": "re_1", "transfer_interval": "weekly", "transfer_day": 5, "bank_account": {"id": 17}}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	account := bankAccount()
+	result, err := client.CreateRecipient(Recipient{
+		BankAccount:          &account,
+		TransferSettings:     TransferSettings{TransferEnabled: true, TransferInterval: WEEKLY, TransferDay: 5},
+		AnticipationSettings: AnticipationSettings{AnticipatableVolumePercentage: 50},
+	})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("re_1", result.ID)
+	assertTest.Equal(WEEKLY, result.TransferInterval)
+	assertTest.Equal(17, result.BankAccount.ID)
+	assertTest.Equal("/recipients", requests[0].URL.Path)
+	assertTest.Equal("weekly", bodies[0]["transfer_interval"])
+	assertTest.Equal(float64(5), bodies[0]["transfer_day"])
+	assertTest.Equal(float64(50), bodies[0]["anticipatable_volume_percentage"])
+	assertTest.Equal("25185465026", bodies[0]["bank_account"].(map[string]interface{})["document_number"])
+	assertTest.Nil(bodies[0]["bank_account_id"])
+}
+
+func TestCreateRecipientBankAccount(t *testing.T) {
+	client := NewClient(WithBaseURL("http://127.0.0.1:0"))
+	account := bankAccount()
+
+	assertTest := assert.New(t)
+	_, err := client.CreateRecipient(Recipient{})
+	assertTest.EqualError(err, "BankAccount is required")
+
+	_, err = client.CreateRecipient(Recipient{BankAccount: &account, BankAccountID: 17})
+	assertTest.EqualError(err, "BankAccountID is invalid. Value: 17")
+
+	account.Agencia = ""
+	_, err = client.CreateRecipient(Recipient{BankAccount: &account})
+	assertTest.EqualError(err, "BankAccount.Agencia is invalid. Value: ")
+
+	_, err = client.CreateRecipient(Recipient{BankAccountID: 17, TransferSettings: TransferSettings{TransferEnabled: true}})
+	assertTest.EqualError(err, "TransferInterval is invalid. Value: ")
+}
+
+func TestUpdateTransferSettings(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "recipient", "id": "re_1", "transfer_enabled": true, "transfer_interval": "monthly", "transfer_day": 10}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.UpdateTransferSettings("re_1", TransferSettings{TransferEnabled: true, TransferInterval: MONTHLY, TransferDay: 10})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(10, result.TransferDay)
+	assertTest.Equal(http.MethodPut, requests[0].Method)
+	assertTest.Equal("/recipients/re_1", requests[0].URL.Path)
+	assertTest.Equal(map[string]interface{}{"transfer_enabled": true, "transfer_interval": "monthly", "transfer_day": float64(10)}, bodies[0])
+}
+
+func TestUpdateAnticipationSettings(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `{"object": "recipient", "id": "re_1", "automatic_anticipation_enabled": true, "automatic_anticipation_type": "1025", "automatic_anticipation_days": [10, 25]}`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	settings := AnticipationSettings{AutomaticAnticipationEnabled: true, AutomaticAnticipationType: ANTICIPATION_1025, AutomaticAnticipationDays: []int{10, 25}}
+	result, err := client.UpdateAnticipationSettings("re_1", settings)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]int{10, 25}, result.AutomaticAnticipationDays)
+	assertTest.Equal(http.MethodPut, requests[0].Method)
+	assertTest.Equal("1025", bodies[0]["automatic_anticipation_type"])
+
+	_, err = client.UpdateAnticipationSettings("re_1", AnticipationSettings{AnticipatableVolumePercentage: -1})
+	assertTest.EqualError(err, "AnticipatableVolumePercentage is invalid. Value: -1")
+	assertTest.Len(requests, 1)
+}
+
+func newRecipientServer(bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/recipients/re_1":
+				io.WriteString(w, `{"id": "re_1", "bank_account": {"id": 17, "document_number": "25185465026"}}`)
+			case r.Method == http.MethodGet && r.URL.Path == "/bank_accounts/18":
+				io.WriteString(w, `{"id": 18, "document_number": "25185465026"}`)
+			case r.Method == http.MethodGet && r.URL.Path == "/bank_accounts/19":
+				io.WriteString(w, `{"id": 19, "document_number": "11144477735"}`)
+			case r.Method == http.MethodPut:
+				body := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				*bodies = append(*bodies, body)
+				io.WriteString(w, `{"id": "re_1", "bank_account": {"id": 20, "document_number": "25185465026"}}`)
+			default:
+				w.WriteHeader(404)
+			}
+		}),
+	)
+}
+
+func TestUpdateRecipientBankAccount(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newRecipientServer(&bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	account := bankAccount()
+	result, err := client.UpdateRecipientBankAccount("re_1", &account, 0)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(20, result.BankAccount.ID)
+	assertTest.Equal("12345678", bodies[0]["bank_account"].(map[string]interface{})["conta"])
+
+	_, err = client.UpdateRecipientBankAccount("re_1", nil, 18)
+	assertTest.Nil(err)
+	assertTest.Equal(float64(18), bodies[1]["bank_account_id"])
+}
+
+func TestUpdateRecipientBankAccountOwnership(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newRecipientServer(&bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	account := bankAccount()
+	account.DocumentNumber = "111.444.777-35"
+	_, err := client.UpdateRecipientBankAccount("re_1", &account, 0)
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Bank account of document 11144477735 does not belong to the owner of recipient re_1")
+
+	_, err = client.UpdateRecipientBankAccount("re_1", nil, 19)
+	var ownership *DocumentOwnershipError
+	assertTest.True(errors.As(err, &ownership))
+	assertTest.Equal("11144477735", ownership.Document)
+	assertTest.Empty(bodies)
+}
+
+func TestListRecipients(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]interface{}
+	server := newOperationServer(200, `[{"id": "re_1"}, {"id": "re_2"}]`, &requests, &bodies)
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListRecipients()
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Recipient().ID)
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal([]string{"re_1", "re_2"}, ids)
+	assertTest.Equal("/recipients", requests[0].URL.Path)
+	assertTest.Equal("100", requests[0].URL.Query().Get("count"))
+}
@@ -0,0 +1,153 @@
+package transactions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// removedFields are left out of the logs.
+var removedFields = map[string]bool{
+	"api_key":        true,
+	"encryption_key": true,
+	"card_hash":      true,
+	"card_cvv":       true,
+	"cvv":            true,
+}
+
+// removedHeaders are left out of the logs.
+var removedHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// MaskCardNumber keeps only the first 6 and the last 4 digits of a card
+// number, such as "411111******1111".
+func MaskCardNumber(number string) string {
+	if len(number) <= 10 {
+		return strings.Repeat("*", len(number))
+	}
+	return number[:6] + strings.Repeat("*", len(number)-10) + number[len(number)-4:]
+}
+
+// MaskDocument hides the digits and letters of a CPF or CNPJ, keeping its
+// formatting, such as "***.***.***-**".
+func MaskDocument(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return '*'
+		}
+		return r
+	}, value)
+}
+
+// RedactBody returns a JSON or form encoded body safe to be logged: card
+// numbers and documents are masked and the fields of removedFields are left
+// out. Any other body is replaced by its size.
+func RedactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		redacted, _ := json.Marshal(redactJSON(value))
+		return string(redacted)
+	}
+
+	if values, err := url.ParseQuery(string(body)); err == nil {
+		return RedactQuery(values).Encode()
+	}
+
+	return fmt.Sprintf("[%v bytes]", len(body))
+}
+
+// RedactQuery returns a copy of values with the same rules as RedactBody.
+// Bracketed keys, such as customer[document_number], are matched by the last
+// name between brackets.
+func RedactQuery(values url.Values) url.Values {
+	redacted := url.Values{}
+	for key, list := range values {
+		name := fieldName(key)
+		if removedFields[name] {
+			continue
+		}
+
+		for _, value := range list {
+			switch name {
+			case "card_number":
+				value = MaskCardNumber(value)
+			case "document_number":
+				value = MaskDocument(value)
+			}
+			redacted.Add(key, value)
+		}
+	}
+	return redacted
+}
+
+// redactURL returns u without its user info and with its query redacted by
+// RedactQuery, so it can be logged or returned in an error.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	if u.RawQuery != "" {
+		redacted.RawQuery = RedactQuery(u.Query()).Encode()
+	}
+	return redacted.String()
+}
+
+// redactPath is redactURL for a path with a query, such as
+// /transactions?customer[document_number]=25185465026.
+func redactPath(path string) string {
+	u, err := url.Parse(path)
+	if err != nil {
+		return strings.SplitN(path, "?", 2)[0]
+	}
+	return redactURL(u)
+}
+
+// RedactHeader returns a copy of header without the credentials.
+func RedactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range removedHeaders {
+		redacted.Del(name)
+	}
+	return redacted
+}
+
+// redactJSON applies the rules of RedactBody to a decoded JSON value. The
+// number of an object whose type is cpf or cnpj, as in the customer
+// documents, is masked as a document.
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		isDocument := v["type"] == CPF.String() || v["type"] == CNPJ.String()
+		for key, field := range v {
+			text, isText := field.(string)
+			switch {
+			case removedFields[key]:
+				delete(v, key)
+			case key == "card_number" && isText:
+				v[key] = MaskCardNumber(text)
+			case (key == "document_number" || (isDocument && key == "number")) && isText:
+				v[key] = MaskDocument(text)
+			default:
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return value
+}
+
+func fieldName(key string) string {
+	key = strings.TrimSuffix(key, "]")
+	if i := strings.LastIndex(key, "["); i >= 0 {
+		return key[i+1:]
+	}
+	return key
+}
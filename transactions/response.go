@@ -0,0 +1,226 @@
+package transactions
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const DATE_LAYOUT = "2006-01-02"
+
+// Date is a calendar date sent by Pagar.me as "2006-01-02", like birthdays
+// and delivery dates. Full timestamps are also accepted when decoding.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DATE_LAYOUT)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		return nil
+	}
+
+	layout := DATE_LAYOUT
+	if strings.Contains(value, "T") {
+		layout = time.RFC3339
+	}
+
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return err
+	}
+
+	d.Time = parsed
+	return nil
+}
+
+// TransactionResponse is the transaction object returned by Pagar.me.
+type TransactionResponse struct {
+	Object                string                 `json:"object"`
+	ID                    int                    `json:"id"`
+	Status                Status                 `json:"status"`
+	RefuseReason          string                 `json:"refuse_reason"`
+	StatusReason          string                 `json:"status_reason"`
+	AcquirerResponseCode  string                 `json:"acquirer_response_code"`
+	AcquirerName          string                 `json:"acquirer_name"`
+	AcquirerID            string                 `json:"acquirer_id"`
+	AuthorizationCode     string                 `json:"authorization_code"`
+	SoftDescriptor        string                 `json:"soft_descriptor"`
+	Tid                   int                    `json:"tid"`
+	Nsu                   int                    `json:"nsu"`
+	DateCreated           time.Time              `json:"date_created"`
+	DateUpdated           time.Time              `json:"date_updated"`
+	Amount                int64                  `json:"amount"`
+	AuthorizedAmount      int64                  `json:"authorized_amount"`
+	PaidAmount            int64                  `json:"paid_amount"`
+	RefundedAmount        int64                  `json:"refunded_amount"`
+	Installments          int                    `json:"installments"`
+	Cost                  int64                  `json:"cost"`
+	CardHolderName        string                 `json:"card_holder_name"`
+	CardLastDigits        string                 `json:"card_last_digits"`
+	CardFirstDigits       string                 `json:"card_first_digits"`
+	CardBrand             CardBrand              `json:"card_brand"`
+	CardPinMode           string                 `json:"card_pin_mode"`
+	CardMagstripeFallback bool                   `json:"card_magstripe_fallback"`
+	CvmPin                bool                   `json:"cvm_pin"`
+	PostbackURL           string                 `json:"postback_url"`
+	PaymentMethod         string                 `json:"payment_method"`
+	CaptureMethod         string                 `json:"capture_method"`
+	AntifraudScore        *float64               `json:"antifraud_score"`
+	BoletoURL             string                 `json:"boleto_url"`
+	BoletoBarcode         string                 `json:"boleto_barcode"`
+	BoletoExpirationDate  *time.Time             `json:"boleto_expiration_date"`
+	Referer               string                 `json:"referer"`
+	IP                    string                 `json:"ip"`
+	SubscriptionID        *int                   `json:"subscription_id"`
+	ReferenceKey          string                 `json:"reference_key"`
+	OrderID               string                 `json:"order_id"`
+	RiskLevel             string                 `json:"risk_level"`
+	ReceiptURL            string                 `json:"receipt_url"`
+	Customer              *CustomerResponse      `json:"customer"`
+	Billing               *Billing               `json:"billing"`
+	Shipping              *ShippingResponse      `json:"shipping"`
+	Items                 []ItemResponse         `json:"items"`
+	Card                  *Card                  `json:"card"`
+	SplitRules            []SplitRuleResponse    `json:"split_rules"`
+	Metadata              map[string]interface{} `json:"metadata"`
+	AntifraudMetadata     map[string]interface{} `json:"antifraud_metadata"`
+}
+
+type CustomerResponse struct {
+	Object         string             `json:"object"`
+	ID             int                `json:"id"`
+	ExternalID     string             `json:"external_id"`
+	Type           string             `json:"type"`
+	Country        string             `json:"country"`
+	DocumentNumber string             `json:"document_number"`
+	DocumentType   string             `json:"document_type"`
+	Name           string             `json:"name"`
+	Email          string             `json:"email"`
+	PhoneNumbers   []string           `json:"phone_numbers"`
+	BornAt         *time.Time         `json:"born_at"`
+	Birthday       Date               `json:"birthday"`
+	Gender         string             `json:"gender"`
+	DateCreated    time.Time          `json:"date_created"`
+	Documents      []DocumentResponse `json:"documents"`
+}
+
+type DocumentResponse struct {
+	Object string `json:"object"`
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Number string `json:"number"`
+}
+
+type Address struct {
+	Object        string `json:"object,omitempty"`
+	ID            int    `json:"id,omitempty"`
+	Street        string `json:"street,omitempty"`
+	StreetNumber  string `json:"street_number,omitempty"`
+	Complementary string `json:"complementary,omitempty"`
+	Neighborhood  string `json:"neighborhood,omitempty"`
+	City          string `json:"city,omitempty"`
+	State         string `json:"state,omitempty"`
+	Zipcode       string `json:"zipcode,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
+type Billing struct {
+	Object  string  `json:"object,omitempty"`
+	ID      int     `json:"id,omitempty"`
+	Name    string  `json:"name,omitempty"`
+	Address Address `json:"address"`
+}
+
+// ShippingResponse is the shipping of a transaction returned by Pagar.me.
+type ShippingResponse struct {
+	Object       string  `json:"object,omitempty"`
+	ID           int     `json:"id,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	Fee          int64   `json:"fee"`
+	DeliveryDate Date    `json:"delivery_date"`
+	Expedited    bool    `json:"expedited"`
+	Address      Address `json:"address"`
+}
+
+// ItemResponse is an item of a transaction returned by Pagar.me.
+type ItemResponse struct {
+	Object    string `json:"object,omitempty"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	UnitPrice int64  `json:"unit_price"`
+	Quantity  int    `json:"quantity"`
+	Category  string `json:"category,omitempty"`
+	Tangible  bool   `json:"tangible"`
+	Venue     string `json:"venue,omitempty"`
+	Date      *Date  `json:"date,omitempty"`
+}
+
+type Card struct {
+	Object         string    `json:"object"`
+	ID             string    `json:"id"`
+	DateCreated    time.Time `json:"date_created"`
+	DateUpdated    time.Time `json:"date_updated"`
+	Brand          CardBrand `json:"brand"`
+	HolderName     string    `json:"holder_name"`
+	FirstDigits    string    `json:"first_digits"`
+	LastDigits     string    `json:"last_digits"`
+	Country        string    `json:"country"`
+	Fingerprint    string    `json:"fingerprint"`
+	Valid          bool      `json:"valid"`
+	ExpirationDate string    `json:"expiration_date"`
+}
+
+// SplitRuleResponse is a split rule of a transaction returned by Pagar.me,
+// which names charge_remainder the charge_remainder_fee of the request.
+type SplitRuleResponse struct {
+	Object              string     `json:"object,omitempty"`
+	ID                  string     `json:"id,omitempty"`
+	RecipientID         string     `json:"recipient_id"`
+	Amount              int64      `json:"amount,omitempty"`
+	Percentage          int        `json:"percentage,omitempty"`
+	Liable              bool       `json:"liable"`
+	ChargeProcessingFee bool       `json:"charge_processing_fee"`
+	ChargeRemainder     bool       `json:"charge_remainder"`
+	DateCreated         *time.Time `json:"date_created,omitempty"`
+	DateUpdated         *time.Time `json:"date_updated,omitempty"`
+}
+
+// RecipientResponse is a recipient of split rules.
+type RecipientResponse struct {
+	Object string `json:"object"`
+	ID     string `json:"id"`
+	TransferSettings
+	AnticipationSettings
+	LastTransfer *time.Time             `json:"last_transfer"`
+	Status       string                 `json:"status"`
+	BankAccount  BankAccount            `json:"bank_account"`
+	PostbackURL  string                 `json:"postback_url"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	DateCreated  time.Time              `json:"date_created"`
+	DateUpdated  time.Time              `json:"date_updated"`
+}
@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the .golden files in testdata")
+
+// decodeGolden decodes a recorded Pagar.me payload and compares the model,
+// encoded again, with its .golden file. Run go test -update after changing
+// the model on purpose.
+func decodeGolden(t *testing.T, name string) TransactionResponse {
+	payload, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := TransactionResponse{}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, _ := json.MarshalIndent(result, "", "  ")
+	golden := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		os.WriteFile(golden, append(actual, '\n'), 0644)
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.New(t).Equal(strings.TrimSpace(string(expected)), string(actual))
+
+	return result
+}
+
+func TestDecodeCreditCardTransaction(t *testing.T) {
+	result := decodeGolden(t, "transaction_credit_card")
+
+	assertTest := assert.New(t)
+	assertTest.Equal(1830855, result.ID)
+	assertTest.Equal(PAID, result.Status)
+	assertTest.Equal(int64(10000), result.PaidAmount)
+	assertTest.Equal(int64(50), result.Cost)
+	assertTest.Equal(42.5, *result.AntifraudScore)
+	assertTest.Equal("", result.BoletoURL)
+	assertTest.Nil(result.BoletoExpirationDate)
+	assertTest.Equal("30621143049", result.Customer.Documents[0].Number)
+	assertTest.Equal(NewDate(1965, time.January, 1), result.Customer.Birthday)
+	assertTest.Equal("06714360", result.Billing.Address.Zipcode)
+	assertTest.Equal(int64(1000), result.Shipping.Fee)
+	assertTest.Equal(NewDate(2000, time.December, 21), result.Shipping.DeliveryDate)
+	assertTest.Len(result.Items, 2)
+	assertTest.Nil(result.Items[0].Date)
+	assertTest.Equal(VISA, result.Card.Brand)
+	assertTest.Equal(85, result.SplitRules[0].Percentage)
+	assertTest.Equal(float64(42), result.Metadata["pedido"])
+}
+
+func TestDecodeBoletoTransaction(t *testing.T) {
+	result := decodeGolden(t, "transaction_boleto")
+
+	assertTest := assert.New(t)
+	assertTest.Equal(WAITING_PAYMENT, result.Status)
+	assertTest.Equal("boleto", result.PaymentMethod)
+	assertTest.Equal("https://pagar.me", result.BoletoURL)
+	assertTest.Equal("1234 5678", result.BoletoBarcode)
+	assertTest.Equal(time.Date(2017, time.August, 21, 3, 0, 0, 0, time.UTC), *result.BoletoExpirationDate)
+	assertTest.Nil(result.AntifraudScore)
+	assertTest.Nil(result.Card)
+	assertTest.Nil(result.Billing)
+	assertTest.True(result.Customer.Birthday.IsZero())
+}
+
+func TestDateJSON(t *testing.T) {
+	date := NewDate(2021, time.March, 9)
+	data, _ := json.Marshal(date)
+
+	assertTest := assert.New(t)
+	assertTest.Equal(`"2021-03-09"`, string(data))
+
+	decoded := Date{}
+	assertTest.Nil(json.Unmarshal([]byte(`"2021-03-09T00:00:00.000Z"`), &decoded))
+	assertTest.Equal(date, decoded)
+
+	data, _ = json.Marshal(Date{})
+	assertTest.Equal("null", string(data))
+}
@@ -0,0 +1,98 @@
+package transactions
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that failed for
+// transient reasons: connection resets, 502/503/504 and 429 responses.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// next decides whether the attempt that produced res/err should be retried
+// and how long to wait before doing it.
+func (p RetryPolicy) next(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	if err != nil {
+		return p.backoff(attempt), isConnectionReset(err)
+	}
+
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return p.backoff(attempt), true
+	case http.StatusTooManyRequests:
+		wait, ok := retryAfter(res.Header.Get("Retry-After"))
+		if !ok {
+			return p.backoff(attempt), true
+		}
+		if p.MaxDelay > 0 && wait > p.MaxDelay {
+			return 0, false
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// backoff is an exponential delay with jitter between half and the full value.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	half := int64(delay / 2)
+	if half <= 0 {
+		return time.Duration(delay)
+	}
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,73 @@
+package transactions
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRetriesGatewayErrors(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+	assertTest := assert.New(t)
+	for _, status := range []int{502, 503, 504} {
+		_, retry := policy.next(1, &http.Response{StatusCode: status}, nil)
+		assertTest.True(retry, status)
+	}
+
+	for _, status := range []int{200, 400, 401, 404, 500} {
+		_, retry := policy.next(1, &http.Response{StatusCode: status}, nil)
+		assertTest.False(retry, status)
+	}
+}
+
+func TestRetryPolicyMaxAttempts(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	_, retry := policy.next(2, &http.Response{StatusCode: 503}, nil)
+
+	assertTest := assert.New(t)
+	assertTest.False(retry)
+}
+
+func TestRetryPolicyConnectionReset(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	assertTest := assert.New(t)
+	_, retry := policy.next(1, nil, &url.Error{Op: "Post", URL: "/transactions", Err: syscall.ECONNRESET})
+	assertTest.True(retry)
+
+	_, retry = policy.next(1, nil, errors.New("x509: certificate signed by unknown authority"))
+	assertTest.False(retry)
+}
+
+func TestRetryPolicyRetryAfter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second}
+	res := &http.Response{StatusCode: 429, Header: http.Header{"Retry-After": []string{"2"}}}
+	wait, retry := policy.next(1, res, nil)
+
+	assertTest := assert.New(t)
+	assertTest.True(retry)
+	assertTest.Equal(2*time.Second, wait)
+
+	res.Header.Set("Retry-After", "60")
+	_, retry = policy.next(1, res, nil)
+	assertTest.False(retry)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	assertTest := assert.New(t)
+	for i := 0; i < 20; i++ {
+		wait := policy.backoff(2)
+		assertTest.GreaterOrEqual(int64(wait), int64(100*time.Millisecond))
+		assertTest.LessOrEqual(int64(wait), int64(200*time.Millisecond))
+
+		wait = policy.backoff(4)
+		assertTest.LessOrEqual(int64(wait), int64(300*time.Millisecond))
+	}
+}
@@ -0,0 +1,152 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const PATH_TRANSACTION_ID = "/transactions/%v"
+const DEFAULT_PAGE_SIZE = 100
+
+// TransactionFilter holds the Pagar.me query filters of ListTransactions.
+// Zero values are not sent.
+type TransactionFilter struct {
+	Status          Status
+	PaymentMethod   string
+	DateCreatedFrom time.Time
+	DateCreatedTo   time.Time
+	DocumentNumber  string
+	Metadata        map[string]string
+	// Count is the page size, DEFAULT_PAGE_SIZE when zero.
+	Count int
+}
+
+func (f TransactionFilter) values() url.Values {
+	q := url.Values{}
+
+	if f.Status != "" {
+		q.Add("status", f.Status.String())
+	}
+	if f.PaymentMethod != "" {
+		q.Add("payment_method", f.PaymentMethod)
+	}
+	if !f.DateCreatedFrom.IsZero() {
+		q.Add("date_created", ">="+strconv.FormatInt(f.DateCreatedFrom.UnixNano()/int64(time.Millisecond), 10))
+	}
+	if !f.DateCreatedTo.IsZero() {
+		q.Add("date_created", "<="+strconv.FormatInt(f.DateCreatedTo.UnixNano()/int64(time.Millisecond), 10))
+	}
+	if f.DocumentNumber != "" {
+		q.Add("customer[document_number]", f.DocumentNumber)
+	}
+	for key, value := range f.Metadata {
+		q.Add("metadata["+key+"]", value)
+	}
+
+	return q
+}
+
+// TransactionIterator walks the result of ListTransactions, requesting the
+// next page when the current one is exhausted.
+//
+//	it := client.ListTransactions(filter)
+//	for it.Next() {
+//		transaction := it.Transaction()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type TransactionIterator struct {
+	pager
+	buffer  []TransactionResponse
+	current TransactionResponse
+}
+
+// Next advances to the next transaction. It returns false at the end of the
+// results or when a page request fails, which is then reported by Err.
+func (it *TransactionIterator) Next() bool {
+	if len(it.buffer) == 0 && !it.next(&it.buffer) {
+		return false
+	}
+
+	it.current, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+func (it *TransactionIterator) Transaction() TransactionResponse {
+	return it.current
+}
+
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// pager requests the pages of a list endpoint for the iterators, count
+// elements at a time or DEFAULT_PAGE_SIZE when count is zero.
+type pager struct {
+	ctx    context.Context
+	client *client
+	path   string
+	query  url.Values
+	count  int
+	page   int
+	done   bool
+	err    error
+}
+
+// next decodes the next page into out, a pointer to a slice, and reports
+// whether it has any element. It returns false after the last page or a
+// failed request.
+func (p *pager) next(out interface{}) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+
+	count := p.count
+	if count <= 0 {
+		count = DEFAULT_PAGE_SIZE
+	}
+
+	p.page++
+	q := url.Values{}
+	for key, values := range p.query {
+		q[key] = values
+	}
+	q.Set("count", strconv.Itoa(count))
+	q.Set("page", strconv.Itoa(p.page))
+
+	if p.err = p.client.request(p.ctx, "GET", p.path+"?"+q.Encode(), nil, out); p.err != nil {
+		return false
+	}
+
+	length := reflect.ValueOf(out).Elem().Len()
+	p.done = length < count
+	return length > 0
+}
+
+func (c *client) GetTransaction(id int) (*TransactionResponse, error) {
+	return c.GetTransactionContext(context.Background(), id)
+}
+
+func (c *client) GetTransactionContext(ctx context.Context, id int) (*TransactionResponse, error) {
+	result := TransactionResponse{}
+
+	if err := c.request(ctx, "GET", fmt.Sprintf(PATH_TRANSACTION_ID, id), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListTransactions returns an iterator over every transaction matching
+// filter. No request is made until Next is called.
+func (c *client) ListTransactions(filter TransactionFilter) *TransactionIterator {
+	return c.ListTransactionsContext(context.Background(), filter)
+}
+
+func (c *client) ListTransactionsContext(ctx context.Context, filter TransactionFilter) *TransactionIterator {
+	return &TransactionIterator{pager: pager{ctx: ctx, client: c, path: PATH_TRANSACTION, query: filter.values(), count: filter.Count}}
+}
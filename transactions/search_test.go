@@ -0,0 +1,177 @@
+package transactions
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGetTransaction(t *testing.T) {
+	var path string
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			io.WriteString(w, `{"id": 1234, "status": "paid", "payment_method": "boleto"}`)
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.GetTransaction(1234)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("/transactions/1234", path)
+	assertTest.Equal(1234, result.ID)
+	assertTest.Equal("boleto", result.PaymentMethod)
+}
+
+func TestGetTransactionNotFound(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+			io.WriteString(w, `{"errors":[{"type":"not_found","parameter_name":null,"message":"Transaction not found"}]}`)
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	result, err := client.GetTransaction(1234)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.True(err.(*APIError).IsNotFound())
+}
+
+func TestListTransactionsPagination(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			switch r.URL.Query().Get("page") {
+			case "1":
+				io.WriteString(w, `[{"id": 1}, {"id": 2}]`)
+			case "2":
+				io.WriteString(w, `[{"id": 3}]`)
+			default:
+				t.Errorf("unexpected page %v", r.URL.Query().Get("page"))
+			}
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListTransactions(TransactionFilter{Count: 2})
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Transaction().ID)
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal([]int{1, 2, 3}, ids)
+	assertTest.Len(queries, 2)
+	assertTest.Equal("2", queries[0].Get("count"))
+	assertTest.False(it.Next())
+}
+
+func TestListTransactionsExactPage(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pages++
+			if r.URL.Query().Get("page") == "1" {
+				io.WriteString(w, `[{"id": 1}, {"id": 2}]`)
+				return
+			}
+			io.WriteString(w, `[]`)
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListTransactions(TransactionFilter{Count: 2})
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Equal(2, count)
+	assertTest.Equal(2, pages)
+}
+
+func TestListTransactionsDefaultPageSize(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			io.WriteString(w, `[{"id": 1}]`)
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListTransactions(TransactionFilter{Status: "paid"})
+	for it.Next() {
+	}
+
+	assertTest := assert.New(t)
+	assertTest.Nil(it.Err())
+	assertTest.Len(queries, 1)
+	assertTest.Equal("100", queries[0].Get("count"))
+	assertTest.Equal("1", queries[0].Get("page"))
+	assertTest.Equal("paid", queries[0].Get("status"))
+}
+
+func TestListTransactionsError(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "1" {
+				io.WriteString(w, `[{"id": 1}]`)
+				return
+			}
+			w.WriteHeader(401)
+		}),
+	)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	it := client.ListTransactions(TransactionFilter{Count: 1})
+
+	assertTest := assert.New(t)
+	assertTest.True(it.Next())
+	assertTest.False(it.Next())
+	assertTest.EqualError(it.Err(), "Pagar.me error. Status: 401 Path: /transactions?count=1&page=2")
+}
+
+func TestTransactionFilterValues(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+	filter := TransactionFilter{
+		Status:          "paid",
+		PaymentMethod:   BOLETO.String(),
+		DateCreatedFrom: from,
+		DateCreatedTo:   to,
+		DocumentNumber:  "25185465026",
+		Metadata:        map[string]string{"pedido": "42"},
+	}
+	q := filter.values()
+
+	assertTest := assert.New(t)
+	assertTest.Equal("paid", q.Get("status"))
+	assertTest.Equal("boleto", q.Get("payment_method"))
+	assertTest.Equal([]string{fmt.Sprintf(">=%v", from.Unix()*1000), fmt.Sprintf("<=%v", to.Unix()*1000)}, q["date_created"])
+	assertTest.Equal("25185465026", q.Get("customer[document_number]"))
+	assertTest.Equal("42", q.Get("metadata[pedido]"))
+	assertTest.Empty(q.Get("count"))
+	assertTest.Empty(q.Get("page"))
+}
@@ -0,0 +1,111 @@
+package transactions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitRule is the request body of a split rule. See
+// TransactionBuilder.SplitRules.
+type SplitRule struct {
+	RecipientID         string `json:"recipient_id"`
+	Amount              Money  `json:"amount,omitempty"`
+	Percentage          int    `json:"percentage,omitempty"`
+	Liable              bool   `json:"liable"`
+	ChargeProcessingFee bool   `json:"charge_processing_fee"`
+	ChargeRemainderFee  bool   `json:"charge_remainder_fee"`
+}
+
+// validSplitRules checks each rule on its own: a recipient, either an
+// Amount in centavos or a whole Percentage from 1 to 100, and the same kind
+// in all rules. Only one recipient can receive the remainder.
+func validSplitRules(rules []SplitRule) *InvalidValueError {
+	if len(rules) == 0 {
+		return &InvalidValueError{"SplitRules", "[]"}
+	}
+
+	recipients := map[string]bool{}
+	remainder := false
+	for _, rule := range rules {
+		switch {
+		case strings.TrimSpace(rule.RecipientID) == "" || recipients[rule.RecipientID]:
+			return &InvalidValueError{"SplitRules.RecipientID", rule.RecipientID}
+		case rule.Amount < 0 || rule.Percentage < 0 || (rule.Amount > 0) == (rule.Percentage > 0):
+			return &InvalidValueError{"SplitRules.Amount", fmt.Sprintf("%v or %v%%", rule.Amount.Decimal(), rule.Percentage)}
+		case rule.Percentage > 100:
+			return &InvalidValueError{"SplitRules.Percentage", fmt.Sprint(rule.Percentage)}
+		case (rule.Percentage > 0) != (rules[0].Percentage > 0):
+			return &InvalidValueError{"SplitRules.Percentage", fmt.Sprint(rule.Percentage)}
+		case rule.ChargeRemainderFee && remainder:
+			return &InvalidValueError{"SplitRules.ChargeRemainderFee", rule.RecipientID}
+		}
+
+		recipients[rule.RecipientID] = true
+		remainder = remainder || rule.ChargeRemainderFee
+	}
+
+	return nil
+}
+
+// splitSum checks that the percentages of rules sum to 100 or that their
+// amounts sum to amount.
+func splitSum(amount Money, rules []SplitRule) error {
+	var sum int64
+	for _, rule := range rules {
+		sum += int64(rule.Amount) + int64(rule.Percentage)
+	}
+
+	if rules[0].Percentage > 0 {
+		if sum != 100 {
+			return &SplitSumError{Percentage: true, Sum: sum, Total: 100}
+		}
+		return nil
+	}
+
+	if Money(sum) != amount {
+		return &SplitSumError{Sum: sum, Total: int64(amount)}
+	}
+	return nil
+}
+
+// SplitAmounts returns the part of amount received by each rule. Amounts are
+// kept as given. Percentages are truncated to the centavo and the remainder
+// centavos go to the rule with ChargeRemainderFee or, without one, one each to
+// the first rules, so that the parts always sum exactly to amount.
+func SplitAmounts(amount Money, rules []SplitRule) ([]Money, error) {
+	if err := validSplitRules(rules); err != nil {
+		return nil, err
+	}
+	if err := splitSum(amount, rules); err != nil {
+		return nil, err
+	}
+
+	parts := make([]Money, len(rules))
+	if rules[0].Percentage == 0 {
+		for i, rule := range rules {
+			parts[i] = rule.Amount
+		}
+		return parts, nil
+	}
+
+	remainder := amount
+	receiver := -1
+	for i, rule := range rules {
+		parts[i] = amount.mulDiv(int64(rule.Percentage), 100)
+		remainder -= parts[i]
+		if rule.ChargeRemainderFee {
+			receiver = i
+		}
+	}
+
+	if receiver >= 0 {
+		parts[receiver] += remainder
+		return parts, nil
+	}
+
+	ratios := make([]int64, len(rules))
+	for i, rule := range rules {
+		ratios[i] = int64(rule.Percentage)
+	}
+	return amount.Allocate(ratios...)
+}
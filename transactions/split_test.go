@@ -0,0 +1,75 @@
+package transactions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitAmountsPercentage(t *testing.T) {
+	rules := []SplitRule{
+		{RecipientID: "re_1", Percentage: 33},
+		{RecipientID: "re_2", Percentage: 33},
+		{RecipientID: "re_3", Percentage: 34, ChargeRemainderFee: true},
+	}
+
+	parts, err := SplitAmounts(Money(1001), rules)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Money{330, 330, 341}, parts)
+}
+
+func TestSplitAmountsWithoutRemainderRule(t *testing.T) {
+	rules := []SplitRule{
+		{RecipientID: "re_1", Percentage: 50},
+		{RecipientID: "re_2", Percentage: 50},
+	}
+
+	parts, err := SplitAmounts(Money(1001), rules)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Money{501, 500}, parts)
+}
+
+func TestSplitAmountsAmount(t *testing.T) {
+	rules := []SplitRule{
+		{RecipientID: "re_1", Amount: 700},
+		{RecipientID: "re_2", Amount: 301},
+	}
+
+	parts, err := SplitAmounts(Money(1001), rules)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal([]Money{700, 301}, parts)
+}
+
+func TestSplitAmountsSum(t *testing.T) {
+	_, err := SplitAmounts(Money(1001), []SplitRule{{RecipientID: "re_1", Percentage: 60}, {RecipientID: "re_2", Percentage: 30}})
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Split rule percentages sum to 90%, not 100%")
+
+	_, err = SplitAmounts(Money(1001), []SplitRule{{RecipientID: "re_1", Amount: 700}, {RecipientID: "re_2", Amount: 300}})
+	assertTest.EqualError(err, "Split rule amounts sum to R$ 10,00, not the amount R$ 10,01")
+}
+
+func TestValidSplitRules(t *testing.T) {
+	tests := []struct {
+		rules []SplitRule
+		err   string
+	}{
+		{nil, "SplitRules is invalid. Value: []"},
+		{[]SplitRule{{Percentage: 100}}, "SplitRules.RecipientID is invalid. Value: "},
+		{[]SplitRule{{RecipientID: "re_1", Percentage: 50}, {RecipientID: "re_1", Percentage: 50}}, "SplitRules.RecipientID is invalid. Value: re_1"},
+		{[]SplitRule{{RecipientID: "re_1"}}, "SplitRules.Amount is invalid. Value: 0.00 or 0%"},
+		{[]SplitRule{{RecipientID: "re_1", Amount: 100, Percentage: 100}}, "SplitRules.Amount is invalid. Value: 1.00 or 100%"},
+		{[]SplitRule{{RecipientID: "re_1", Percentage: 101}}, "SplitRules.Percentage is invalid. Value: 101"},
+		{[]SplitRule{{RecipientID: "re_1", Amount: 100}, {RecipientID: "re_2", Percentage: 50}}, "SplitRules.Percentage is invalid. Value: 50"},
+		{[]SplitRule{{RecipientID: "re_1", Percentage: 50, ChargeRemainderFee: true}, {RecipientID: "re_2", Percentage: 50, ChargeRemainderFee: true}}, "SplitRules.ChargeRemainderFee is invalid. Value: re_2"},
+	}
+
+	for _, test := range tests {
+		assert.New(t).EqualError(validSplitRules(test.rules), test.err)
+	}
+}
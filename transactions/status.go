@@ -0,0 +1,128 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Status string
+
+const (
+	PROCESSING      Status = "processing"
+	AUTHORIZED      Status = "authorized"
+	PAID            Status = "paid"
+	REFUNDED        Status = "refunded"
+	WAITING_PAYMENT Status = "waiting_payment"
+	PENDING_REFUND  Status = "pending_refund"
+	REFUSED         Status = "refused"
+	CHARGEDBACK     Status = "chargedback"
+	ANALYZING       Status = "analyzing"
+	PENDING_REVIEW  Status = "pending_review"
+)
+
+// transitions lists the statuses each status may move to. Statuses without
+// entries are final.
+var transitions = map[Status][]Status{
+	PROCESSING:      {AUTHORIZED, PAID, WAITING_PAYMENT, REFUSED, ANALYZING, PENDING_REVIEW},
+	ANALYZING:       {AUTHORIZED, PAID, REFUSED, PENDING_REVIEW},
+	PENDING_REVIEW:  {AUTHORIZED, PAID, REFUSED},
+	AUTHORIZED:      {PAID, REFUSED, REFUNDED},
+	WAITING_PAYMENT: {PAID, REFUSED},
+	PAID:            {PENDING_REFUND, REFUNDED, CHARGEDBACK},
+	PENDING_REFUND:  {REFUNDED, PAID},
+	REFUNDED:        {},
+	REFUSED:         {},
+	CHARGEDBACK:     {},
+}
+
+func (s Status) String() string {
+	return string(s)
+}
+
+// Valid reports whether s is a status known by this package.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a transaction in status s may move to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no further status change is expected.
+func (s Status) IsFinal() bool {
+	return s.Valid() && len(transitions[s]) == 0
+}
+
+func (s Status) IsPaid() bool {
+	return s == PAID
+}
+
+// StatusError is returned by WaitForStatus when the transaction reaches a
+// final status other than the expected ones.
+type StatusError struct {
+	ID      int
+	Status  Status
+	Targets []Status
+}
+
+func (e *StatusError) Error() string {
+	targets := make([]string, len(e.Targets))
+	for i, target := range e.Targets {
+		targets[i] = target.String()
+	}
+	return fmt.Sprintf("Transaction %v is %v. Expected: %v", e.ID, e.Status, strings.Join(targets, ", "))
+}
+
+// WaitForStatus polls the transaction until its status is one of targets,
+// as when waiting for a boleto to be paid. The interval between requests
+// doubles from the client poll interval up to its maximum. It stops with a
+// StatusError when a different final status is reached and with the context
+// error when ctx is done.
+func (c *client) WaitForStatus(ctx context.Context, id int, targets ...Status) (*TransactionResponse, error) {
+	interval := c.pollInterval
+	var previous Status
+
+	for {
+		transaction, err := c.GetTransactionContext(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range targets {
+			if transaction.Status == target {
+				return transaction, nil
+			}
+		}
+
+		if previous != "" && previous != transaction.Status && !previous.CanTransitionTo(transaction.Status) {
+			c.log().Warn("Unexpected status transition", "id", id, "from", previous, "to", transaction.Status)
+		}
+		previous = transaction.Status
+
+		if transaction.Status.IsFinal() {
+			return transaction, &StatusError{id, transaction.Status, targets}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return transaction, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > c.maxPollInterval {
+			interval = c.maxPollInterval
+		}
+	}
+}
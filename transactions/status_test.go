@@ -0,0 +1,99 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.True(PROCESSING.CanTransitionTo(WAITING_PAYMENT))
+	assertTest.True(WAITING_PAYMENT.CanTransitionTo(PAID))
+	assertTest.True(AUTHORIZED.CanTransitionTo(PAID))
+	assertTest.True(PAID.CanTransitionTo(PENDING_REFUND))
+	assertTest.True(PENDING_REFUND.CanTransitionTo(REFUNDED))
+	assertTest.False(REFUSED.CanTransitionTo(PAID))
+	assertTest.False(WAITING_PAYMENT.CanTransitionTo(REFUNDED))
+	assertTest.False(PAID.CanTransitionTo(PROCESSING))
+}
+
+func TestStatusIsFinal(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.True(REFUSED.IsFinal())
+	assertTest.True(REFUNDED.IsFinal())
+	assertTest.True(CHARGEDBACK.IsFinal())
+	assertTest.False(PAID.IsFinal())
+	assertTest.False(WAITING_PAYMENT.IsFinal())
+	assertTest.False(Status("unknown").IsFinal())
+}
+
+func TestStatusIsPaid(t *testing.T) {
+	assertTest := assert.New(t)
+	assertTest.True(PAID.IsPaid())
+	assertTest.False(AUTHORIZED.IsPaid())
+	assertTest.True(PAID.Valid())
+	assertTest.False(Status("unknown").Valid())
+}
+
+func newStatusServer(statuses ...Status) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := statuses[len(statuses)-1]
+			if requests < len(statuses) {
+				status = statuses[requests]
+			}
+			requests++
+			io.WriteString(w, fmt.Sprintf(`{"id": 1234, "status": "%v"}`, status))
+		}),
+	)
+	return server, &requests
+}
+
+func TestWaitForStatus(t *testing.T) {
+	server, requests := newStatusServer(WAITING_PAYMENT, WAITING_PAYMENT, PAID)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithPollInterval(time.Millisecond, 2*time.Millisecond))
+	result, err := client.WaitForStatus(context.Background(), 1234, PAID)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(PAID, result.Status)
+	assertTest.Equal(3, *requests)
+}
+
+func TestWaitForStatusFinal(t *testing.T) {
+	server, _ := newStatusServer(PROCESSING, REFUSED)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithPollInterval(time.Millisecond, time.Millisecond))
+	result, err := client.WaitForStatus(context.Background(), 1234, PAID, AUTHORIZED)
+
+	assertTest := assert.New(t)
+	assertTest.Equal(REFUSED, result.Status)
+	assertTest.EqualError(err, "Transaction 1234 is refused. Expected: paid, authorized")
+}
+
+func TestWaitForStatusTimeout(t *testing.T) {
+	server, _ := newStatusServer(WAITING_PAYMENT)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithPollInterval(time.Millisecond, 5*time.Millisecond))
+	result, err := client.WaitForStatus(ctx, 1234, PAID)
+
+	assertTest := assert.New(t)
+	assertTest.ErrorIs(err, context.DeadlineExceeded)
+	if result != nil {
+		assertTest.Equal(WAITING_PAYMENT, result.Status)
+	}
+}
@@ -2,6 +2,7 @@ package transactions
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -9,25 +10,29 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
+	"pagarme/documents"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type PaymentMethod int
 type TypeCustomer int
-type DocumentType int
+type DocumentType = documents.DocumentType
 type AuthenticationMethod int
 
 const BASE_URL = "https://api.pagar.me/1"
 const PATH_TRANSACTION = "/transactions"
 const PATH_HASH = "/transactions/card_hash_key"
-const API_KEY = "ak_test_qCS4GVwDKJhzbTn0Z3KIU4p4k79U17"
+const METADATA_IDEMPOTENCY_KEY = "idempotency_key"
+const USER_AGENT = "pagarme-go"
+const DEFAULT_POLL_INTERVAL = 2 * time.Second
+const DEFAULT_MAX_POLL_INTERVAL = time.Minute
 
 const (
 	CREDIT_CARD PaymentMethod = iota
@@ -40,8 +45,8 @@ const (
 )
 
 const (
-	CPF DocumentType = iota
-	CNPJ
+	CPF  = documents.CPF
+	CNPJ = documents.CNPJ
 )
 
 const (
@@ -58,10 +63,6 @@ func (t TypeCustomer) String() string {
 	return [...]string{"individual", "corporation"}[t]
 }
 
-func (d DocumentType) String() string {
-	return [...]string{"cpf", "cnpj"}[d]
-}
-
 func NewTypeCustomer(value string) TypeCustomer {
 
 	if strings.ToLower(value) == "corporation" {
@@ -71,76 +72,55 @@ func NewTypeCustomer(value string) TypeCustomer {
 	return INDIVIDUAL
 }
 
-type publicKey struct {
+// PublicKey is the RSA key returned by /transactions/card_hash_key, used to
+// encrypt the card data in a card hash.
+type PublicKey struct {
 	Id        int    `json:"id"`
 	PublicKey string `json:"public_key"`
 	Ip        string `json:"ip"`
 }
 
-type transactionResponse struct {
-	Status                string      `json:"status"`
-	RefuseReason          string      `json:"refuse_reason"`
-	StatusReason          string      `json:"status_reason"`
-	AcquirerResponseCode  string      `json:"acquirer_response_code"`
-	AcquirerName          string      `json:"acquirer_name"`
-	AcquirerID            string      `json:"acquirer_id"`
-	Tid                   int         `json:"tid"`
-	Nsu                   int         `json:"nsu"`
-	DateCreated           time.Time   `json:"date_created"`
-	DateUpdated           time.Time   `json:"date_updated"`
-	Amount                int         `json:"amount"`
-	Installments          int         `json:"installments"`
-	ID                    int         `json:"id"`
-	CardHolderName        string      `json:"card_holder_name"`
-	CardLastDigits        string      `json:"card_last_digits"`
-	CardFirstDigits       string      `json:"card_first_digits"`
-	CardBrand             string      `json:"card_brand"`
-	CardPinMode           interface{} `json:"card_pin_mode"`
-	CardMagstripeFallback bool        `json:"card_magstripe_fallback"`
-	CvmPin                bool        `json:"cvm_pin"`
-	PaymentMethod         string      `json:"payment_method"`
-	CaptureMethod         string      `json:"capture_method"`
-	BoletoURL             interface{} `json:"boleto_url"`
-	BoletoBarcode         interface{} `json:"boleto_barcode"`
-	BoletoExpirationDate  interface{} `json:"boleto_expiration_date"`
-	Referer               string      `json:"referer"`
-	IP                    string      `json:"ip"`
-	Card                  struct {
-		ID             string    `json:"id"`
-		DateCreated    time.Time `json:"date_created"`
-		DateUpdated    time.Time `json:"date_updated"`
-		Brand          string    `json:"brand"`
-		HolderName     string    `json:"holder_name"`
-		FirstDigits    string    `json:"first_digits"`
-		LastDigits     string    `json:"last_digits"`
-		Country        string    `json:"country"`
-		Fingerprint    string    `json:"fingerprint"`
-		Valid          bool      `json:"valid"`
-		ExpirationDate string    `json:"expiration_date"`
-	} `json:"card"`
-}
-
 type TransactionI interface {
 	marshal()
-	CreateCardHash()
-}
-
-type transaction struct {
-	ApiKey             string `json:"api_key,omitempty"`
-	Amount             int64  `json:"amount"`
-	CardHash           string `json:"card_hash,omitempty"`
-	CardHolderName     string `json:"card_holder_name,omitempty"`
-	CardExpirationDate string `json:"card_expiration_date,omitempty"`
-	CardNumber         string `json:"card_number,omitempty"`
-	CardCVV            string `json:"card_cvv,omitempty"`
-	PaymentMethod      string `json:"payment_method,omitempty"`
+	CreateCardHash(key PublicKey) error
+}
+
+// Transaction is the request body of a transaction, created by
+// TransactionBuilder.Build.
+type Transaction struct {
+	ApiKey             string            `json:"api_key,omitempty"`
+	Amount             Money             `json:"amount"`
+	CardHash           string            `json:"card_hash,omitempty"`
+	CardHolderName     string            `json:"card_holder_name,omitempty"`
+	CardExpirationDate string            `json:"card_expiration_date,omitempty"`
+	CardNumber         string            `json:"card_number,omitempty"`
+	CardCVV            string            `json:"card_cvv,omitempty"`
+	CardID             string            `json:"card_id,omitempty"`
+	PaymentMethod      string            `json:"payment_method,omitempty"`
+	Installments       int               `json:"installments,omitempty"`
+	Capture            *bool             `json:"capture,omitempty"`
+	PostbackURL        string            `json:"postback_url,omitempty"`
+	Async              *bool             `json:"async,omitempty"`
+	BoletoExpiration   string            `json:"boleto_expiration_date,omitempty"`
+	BoletoInstructions string            `json:"boleto_instructions,omitempty"`
+	BoletoFine         *boletoCharge     `json:"boleto_fine,omitempty"`
+	BoletoInterest     *boletoCharge     `json:"boleto_interest,omitempty"`
+	BoletoRules        []BoletoRule      `json:"boleto_rules,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
 	Customer           struct {
 		ExternalId   string     `json:"number,omitempty"`
 		Name         string     `json:"name,omitempty"`
 		Country      string     `json:"country,omitempty"`
 		CustomerType string     `json:"type,omitempty"`
+		Email        string     `json:"email,omitempty"`
+		PhoneNumbers []string   `json:"phone_numbers,omitempty"`
+		Birthday     *Date      `json:"birthday,omitempty"`
 		Documents    []document `json:"documents,omitempty"`
 	} `json:"customer,omitempty"`
+	Billing    *Billing    `json:"billing,omitempty"`
+	Shipping   *Shipping   `json:"shipping,omitempty"`
+	Items      []Item      `json:"items,omitempty"`
+	SplitRules []SplitRule `json:"split_rules,omitempty"`
 }
 
 type document struct {
@@ -148,114 +128,465 @@ type document struct {
 	Number       string `json:"number,omitempty"`
 }
 
-func (t *transaction) marshal() ([]byte, error) {
-	return json.Marshal(t)
+// Shipping is the request body of the delivery of the order. See
+// TransactionBuilder.Shipping.
+type Shipping struct {
+	Name         string  `json:"name,omitempty"`
+	Fee          Money   `json:"fee"`
+	DeliveryDate Date    `json:"delivery_date"`
+	Expedited    bool    `json:"expedited"`
+	Address      Address `json:"address"`
 }
 
-func (t *transaction) CreateCardHash(key publicKey) {
-	rsaPublicKey := createRsaPublicKey(key.PublicKey)
+// Item is the request body of a line item of the order. See
+// TransactionBuilder.AddItem.
+type Item struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	UnitPrice Money  `json:"unit_price"`
+	Quantity  int    `json:"quantity"`
+	Category  string `json:"category,omitempty"`
+	Tangible  bool   `json:"tangible"`
+	Venue     string `json:"venue,omitempty"`
+	Date      *Date  `json:"date,omitempty"`
+}
 
-	params := url.Values{}
-	params.Add("card_number", t.CardNumber)
-	params.Add("card_holder_name", t.CardHolderName)
-	params.Add("card_expiration_date", t.CardExpirationDate)
-	params.Add("card_cvv", t.CardCVV)
-	querystring := params.Encode()
+func (t *Transaction) marshal() ([]byte, error) {
+	return json.Marshal(t)
+}
 
-	pkcs1padding, err := rsa.EncryptPKCS1v15(rand.Reader, rsaPublicKey, []byte(querystring))
+// IdempotencyKey is the client generated key stored in the transaction
+// metadata. It is used to find a transaction created by an attempt whose
+// response was lost before sending it again.
+func (t *Transaction) IdempotencyKey() string {
+	return t.Metadata[METADATA_IDEMPOTENCY_KEY]
+}
 
+// CreateCardHash replaces the card data of the transaction with a card hash
+// encrypted with key. The transaction is left unchanged when it fails.
+func (t *Transaction) CreateCardHash(key PublicKey) error {
+	card := CardData{t.CardNumber, t.CardHolderName, t.CardExpirationDate, t.CardCVV}
+	cardHash, err := card.CardHash(key)
 	if err != nil {
-		log.Fatal("failed to EncryptPKCS1v15 " + err.Error())
+		return err
 	}
-	pkcs1padding64 := b64.StdEncoding.EncodeToString(pkcs1padding)
 
 	t.CardCVV = ""
 	t.CardExpirationDate = ""
 	t.CardHolderName = ""
 	t.CardNumber = ""
-	t.CardHash = strconv.Itoa(key.Id) + "_" + pkcs1padding64
+	t.CardHash = cardHash
 
+	return nil
+}
+
+// CardHash encrypts the card with key, in the format of the card_hash
+// parameter.
+func (card CardData) CardHash(key PublicKey) (string, error) {
+	rsaPublicKey, err := createRsaPublicKey(key.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Add("card_number", card.Number)
+	params.Add("card_holder_name", card.HolderName)
+	params.Add("card_expiration_date", card.ExpirationDate)
+	params.Add("card_cvv", card.CVV)
+	querystring := params.Encode()
+
+	pkcs1padding, err := rsa.EncryptPKCS1v15(rand.Reader, rsaPublicKey, []byte(querystring))
+	if err != nil {
+		return "", &EncryptionError{err}
+	}
+	pkcs1padding64 := b64.StdEncoding.EncodeToString(pkcs1padding)
+
+	return strconv.Itoa(key.Id) + "_" + pkcs1padding64, nil
 }
 
 type TransactionBuilderI interface {
-	Build()
-	Amount(value float64) *TransactionBuilder
+	Build() (Transaction, error)
+	Amount(value Money) *TransactionBuilder
 	PaymentMethod(value PaymentMethod) *TransactionBuilder
+	Capture(value bool) *TransactionBuilder
+	Installments(value int) (*TransactionBuilder, error)
+	PostbackURL(value string) (*TransactionBuilder, error)
 	TypeCustomer(value TypeCustomer) *TransactionBuilder
 	CardHolderName(value string) (*TransactionBuilder, error)
 	Name(value string) (*TransactionBuilder, error)
+	Clock(now func() time.Time) *TransactionBuilder
+	Async(value bool) *TransactionBuilder
+	BoletoExpirationDate(value time.Time) (*TransactionBuilder, error)
+	BoletoInstructions(value string) (*TransactionBuilder, error)
+	BoletoFine(value BoletoCharge) (*TransactionBuilder, error)
+	BoletoInterest(value BoletoCharge) (*TransactionBuilder, error)
+	BoletoRules(value BoletoRule) (*TransactionBuilder, error)
 	CardExpirationDate(value string) (*TransactionBuilder, error)
 	CardNumber(value string) (*TransactionBuilder, error)
 	CardCVV(value string) (*TransactionBuilder, error)
+	CardID(value string) (*TransactionBuilder, error)
 	Country(value string) (*TransactionBuilder, error)
 	Document(value string) (*TransactionBuilder, error)
+	Email(value string) (*TransactionBuilder, error)
+	PhoneNumber(value string) (*TransactionBuilder, error)
+	ClearPhoneNumbers() *TransactionBuilder
+	Birthday(value Date) (*TransactionBuilder, error)
+	Billing(value Billing) (*TransactionBuilder, error)
+	Shipping(value Shipping) (*TransactionBuilder, error)
+	AddItem(value Item) (*TransactionBuilder, error)
+	ClearItems() *TransactionBuilder
+	SplitRules(rules ...SplitRule) (*TransactionBuilder, error)
 }
 
 type TransactionBuilder struct {
-	transaction transaction
+	transaction    Transaction
+	cardBrand      CardBrand
+	boletoFine     BoletoCharge
+	boletoInterest BoletoCharge
+	phoneNumbers   int
+	items          int
+	now            func() time.Time
+	invalid        []error
 }
 
-func (b *TransactionBuilder) Build() transaction {
-	b.transaction.ApiKey = API_KEY
+// Build returns the transaction and resets the builder. When a setter
+// received an invalid value or a field required by the payment method is
+// missing it returns a *BuildError listing all of them, and the builder is
+// kept so the fields can be fixed.
+func (b *TransactionBuilder) Build() (Transaction, error) {
+	if errs := b.validate(); len(errs) > 0 {
+		return Transaction{}, &BuildError{errs}
+	}
+
+	if b.transaction.IdempotencyKey() == "" {
+		if b.transaction.Metadata == nil {
+			b.transaction.Metadata = map[string]string{}
+		}
+		b.transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = newIdempotencyKey()
+	}
 	transactionFinal := b.transaction
-	b.transaction = transaction{}
-	return transactionFinal
+	*b = TransactionBuilder{now: b.now}
+	return transactionFinal, nil
 }
 
-func (b *TransactionBuilder) Amount(value float64) *TransactionBuilder {
-	floatString := fmt.Sprintf("%.2f", value)
-	floatString = strings.Replace(floatString, ".", "", -1)
-	floatString = strings.Replace(floatString, ",", "", -1)
-	amount, _ := strconv.ParseInt(floatString, 10, 64)
-	b.transaction.Amount = amount
+// validate returns the errors of the setters followed by the missing
+// required fields: the card data for CREDIT_CARD and the document for BOLETO,
+// which is also paid in a single installment. The boleto options are refused
+// for CREDIT_CARD. When there are items, their total plus the shipping fee
+// must be the amount, and so must the split rules.
+func (b *TransactionBuilder) validate() []error {
+	errs := append([]error{}, b.invalid...)
+
+	require := func(valueParam string, present bool) {
+		if !present && !b.hasInvalid(valueParam) {
+			errs = append(errs, &MissingValueError{valueParam})
+		}
+	}
+
+	t := b.transaction
+
+	require("Amount", t.Amount > 0)
+	require("PaymentMethod", t.PaymentMethod != "")
+
+	if len(t.Items) > 0 && t.Amount > 0 {
+		items := itemsTotal(t.Items)
+		var fee Money
+		if t.Shipping != nil {
+			fee = t.Shipping.Fee
+		}
+		if items+fee != t.Amount {
+			errs = append(errs, &AmountMismatchError{t.Amount, items, fee})
+		}
+	}
+
+	if len(t.SplitRules) > 0 && t.Amount > 0 {
+		if err := splitSum(t.Amount, t.SplitRules); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch t.PaymentMethod {
+	case CREDIT_CARD.String():
+		if t.CardID != "" {
+			if t.CardNumber != "" || t.CardHolderName != "" || t.CardExpirationDate != "" {
+				errs = append(errs, &InvalidValueError{"CardID", t.CardID})
+			}
+		} else {
+			require("CardNumber", t.CardNumber != "")
+			require("CardHolderName", t.CardHolderName != "")
+			require("CardExpirationDate", t.CardExpirationDate != "")
+			require("CardCVV", t.CardCVV != "")
+		}
+
+		if t.CardCVV != "" && t.CardNumber != "" && !b.hasInvalid("CardCVV") && len(t.CardCVV) != b.cardBrand.CVVLength() {
+			errs = append(errs, &InvalidValueError{"CardCVV", t.CardCVV})
+		}
+
+		boletoOptions := []struct {
+			valueParam string
+			set        bool
+			value      string
+		}{
+			{"BoletoExpirationDate", t.BoletoExpiration != "", t.BoletoExpiration},
+			{"BoletoInstructions", t.BoletoInstructions != "", t.BoletoInstructions},
+			{"BoletoFine", t.BoletoFine != nil, fmt.Sprint(b.boletoFine)},
+			{"BoletoInterest", t.BoletoInterest != nil, fmt.Sprint(b.boletoInterest)},
+			{"BoletoRules", len(t.BoletoRules) > 0, fmt.Sprint(t.BoletoRules)},
+		}
+		for _, option := range boletoOptions {
+			if option.set {
+				errs = append(errs, &InvalidValueError{option.valueParam, option.value})
+			}
+		}
+	case BOLETO.String():
+		require("Document.Number", len(t.Customer.Documents) > 0)
+
+		if t.CardID != "" {
+			errs = append(errs, &InvalidValueError{"CardID", t.CardID})
+		}
+
+		if t.Installments > 1 {
+			errs = append(errs, &InvalidValueError{"Installments", strconv.Itoa(t.Installments)})
+		}
+	}
+
+	return errs
+}
+
+// setInvalid records err, replacing a previous error of the same field, and
+// returns it.
+func (b *TransactionBuilder) setInvalid(err *InvalidValueError) error {
+	b.setValid(err.ValueParam)
+	b.invalid = append(b.invalid, err)
+	return err
+}
+
+// setValid forgets the error of a field set again with a valid value,
+// including the errors of its inner fields, such as Billing.Address.Zipcode
+// for Billing, and of each call of a repeated field, such as PhoneNumber[1]
+// and Item[1].Title for PhoneNumber and Item.
+func (b *TransactionBuilder) setValid(valueParam string) {
+	invalid := b.invalid[:0]
+	for _, err := range b.invalid {
+		param := err.(*InvalidValueError).ValueParam
+		if param != valueParam && !strings.HasPrefix(param, valueParam+".") && !strings.HasPrefix(param, valueParam+"[") {
+			invalid = append(invalid, err)
+		}
+	}
+	b.invalid = invalid
+}
+
+func (b *TransactionBuilder) hasInvalid(valueParam string) bool {
+	for _, err := range b.invalid {
+		if err.(*InvalidValueError).ValueParam == valueParam {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *TransactionBuilder) Amount(value Money) *TransactionBuilder {
+	b.transaction.Amount = value
+	return b
+}
+
+// Capture(false) only authorizes a credit card transaction. It must be
+// captured later with client.Capture.
+func (b *TransactionBuilder) Capture(value bool) *TransactionBuilder {
+	b.transaction.Capture = &value
 	return b
 }
 
+// Installments splits a credit card charge in 1 to MAX_INSTALLMENTS
+// installments. The amount must already include the interest, see
+// CalculateInstallments.
+func (b *TransactionBuilder) Installments(value int) (*TransactionBuilder, error) {
+
+	if value < 1 || value > MAX_INSTALLMENTS {
+		return b, b.setInvalid(&InvalidValueError{"Installments", strconv.Itoa(value)})
+	}
+
+	b.setValid("Installments")
+	b.transaction.Installments = value
+	return b, nil
+}
+
+// Async makes Pagar.me answer before processing the transaction, which is
+// then reported by postbacks. Pagar.me processes it synchronously by default.
+func (b *TransactionBuilder) Async(value bool) *TransactionBuilder {
+	b.transaction.Async = &value
+	return b
+}
+
+// BoletoExpirationDate is the due date of the boleto, which must be after
+// today. See NextBusinessDay and AddBusinessDays.
+func (b *TransactionBuilder) BoletoExpirationDate(value time.Time) (*TransactionBuilder, error) {
+	date := value.Format(BOLETO_DATE_LAYOUT)
+
+	if date <= b.clock().In(value.Location()).Format(BOLETO_DATE_LAYOUT) {
+		return b, b.setInvalid(&InvalidValueError{"BoletoExpirationDate", date})
+	}
+
+	b.setValid("BoletoExpirationDate")
+	b.transaction.BoletoExpiration = date
+	return b, nil
+}
+
+// BoletoInstructions is the text printed on the boleto, up to
+// MAX_BOLETO_INSTRUCTIONS characters.
+func (b *TransactionBuilder) BoletoInstructions(value string) (*TransactionBuilder, error) {
+
+	if strings.TrimSpace(value) == "" || utf8.RuneCountInString(value) > MAX_BOLETO_INSTRUCTIONS {
+		return b, b.setInvalid(&InvalidValueError{"BoletoInstructions", value})
+	}
+
+	b.setValid("BoletoInstructions")
+	b.transaction.BoletoInstructions = value
+	return b, nil
+}
+
+// BoletoFine is charged once on a boleto paid value.Days after the
+// expiration date.
+func (b *TransactionBuilder) BoletoFine(value BoletoCharge) (*TransactionBuilder, error) {
+
+	if !value.valid() {
+		return b, b.setInvalid(&InvalidValueError{"BoletoFine", value.String()})
+	}
+
+	b.setValid("BoletoFine")
+	b.boletoFine = value
+	b.transaction.BoletoFine = value.request()
+	return b, nil
+}
+
+// BoletoInterest is charged from value.Days after the expiration date: a
+// fixed amount per day or a percentage per month.
+func (b *TransactionBuilder) BoletoInterest(value BoletoCharge) (*TransactionBuilder, error) {
+
+	if !value.valid() {
+		return b, b.setInvalid(&InvalidValueError{"BoletoInterest", value.String()})
+	}
+
+	b.setValid("BoletoInterest")
+	b.boletoInterest = value
+	b.transaction.BoletoInterest = value.request()
+	return b, nil
+}
+
+// BoletoRules sets the rule of payments after the expiration date,
+// STRICT_EXPIRATION_DATE or NO_STRICT.
+func (b *TransactionBuilder) BoletoRules(value BoletoRule) (*TransactionBuilder, error) {
+
+	if value != STRICT_EXPIRATION_DATE && value != NO_STRICT {
+		return b, b.setInvalid(&InvalidValueError{"BoletoRules", string(value)})
+	}
+
+	b.setValid("BoletoRules")
+	b.transaction.BoletoRules = []BoletoRule{value}
+	return b, nil
+}
+
+// PostbackURL is the address Pagar.me notifies on every status change of the
+// transaction. See the postback package.
+func (b *TransactionBuilder) PostbackURL(value string) (*TransactionBuilder, error) {
+
+	postbackURL, err := url.Parse(value)
+
+	if err != nil || (postbackURL.Scheme != "http" && postbackURL.Scheme != "https") || postbackURL.Host == "" {
+		return b, b.setInvalid(&InvalidValueError{"PostbackURL", value})
+	}
+
+	b.setValid("PostbackURL")
+	b.transaction.PostbackURL = value
+	return b, nil
+}
+
 func (b *TransactionBuilder) CardHolderName(value string) (*TransactionBuilder, error) {
 
 	if value == "" {
-		return nil, &InvalidValueError{"CardHolderName", value}
+		return nil, b.setInvalid(&InvalidValueError{"CardHolderName", value})
 	}
 
+	b.setValid("CardHolderName")
 	b.transaction.CardHolderName = value
 	return b, nil
 }
 
+// Clock replaces time.Now as the current time used to validate the card
+// and boleto expiration dates.
+func (b *TransactionBuilder) Clock(now func() time.Time) *TransactionBuilder {
+	b.now = now
+	return b
+}
+
+func (b *TransactionBuilder) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// CardExpirationDate accepts MMYY, MM/YY or MM/YYYY of a card not expired,
+// and sends it as MMYY.
 func (b *TransactionBuilder) CardExpirationDate(value string) (*TransactionBuilder, error) {
-	regex, _ := regexp.Compile("^\\d{4}$")
+	expiry, err := ParseCardExpiry(value)
+	if err != nil {
+		return nil, b.setInvalid(err.(*InvalidValueError))
+	}
 
-	if !regex.MatchString(value) {
-		return nil, &InvalidValueError{"CardExpirationDate", value}
+	if expiry.Expired(b.clock()) {
+		return nil, b.setInvalid(&InvalidValueError{"CardExpirationDate", value})
 	}
 
-	b.transaction.CardExpirationDate = value
+	b.setValid("CardExpirationDate")
+	b.transaction.CardExpirationDate = expiry.String()
 	return b, nil
 }
 
+// CardNumber accepts a number with a valid Luhn check digit, a known brand
+// and a length issued by that brand.
 func (b *TransactionBuilder) CardNumber(value string) (*TransactionBuilder, error) {
 
-	regex, _ := regexp.Compile("^\\d+$")
+	brand := DetectCardBrand(value)
 
-	if !regex.MatchString(value) {
-		return nil, &InvalidValueError{"CardNumber", value}
+	if !ValidLuhn(value) || !brand.ValidLength(len(value)) {
+		return nil, b.setInvalid(&InvalidValueError{"CardNumber", value})
 	}
 
+	b.setValid("CardNumber")
 	b.transaction.CardNumber = value
+	b.cardBrand = brand
 	return b, nil
 }
 
+// CardCVV is the security code of 3 or 4 digits. Its length must match the
+// brand of the card number, which is checked by Build so the setters can be
+// called in any order. The brand of a stored card is not known, so after
+// CardID either length is accepted.
 func (b *TransactionBuilder) CardCVV(value string) (*TransactionBuilder, error) {
 
-	regex, _ := regexp.Compile("^\\d{3}$")
-
-	if !regex.MatchString(value) {
-		return nil, &InvalidValueError{"CardCVV", value}
+	if !cvvRegex.MatchString(value) {
+		return nil, b.setInvalid(&InvalidValueError{"CardCVV", value})
 	}
 
+	b.setValid("CardCVV")
 	b.transaction.CardCVV = value
 	return b, nil
 }
 
+// CardID charges a card stored with client.CreateCard instead of the card
+// number, holder name and expiration date. The CVV is optional.
+func (b *TransactionBuilder) CardID(value string) (*TransactionBuilder, error) {
+
+	if !cardIDRegex.MatchString(value) {
+		return b, b.setInvalid(&InvalidValueError{"CardID", value})
+	}
+
+	b.setValid("CardID")
+	b.transaction.CardID = value
+	return b, nil
+}
+
 func (b *TransactionBuilder) PaymentMethod(value PaymentMethod) *TransactionBuilder {
 	b.transaction.PaymentMethod = value.String()
 	return b
@@ -264,9 +595,10 @@ func (b *TransactionBuilder) PaymentMethod(value PaymentMethod) *TransactionBuil
 func (b *TransactionBuilder) Name(value string) (*TransactionBuilder, error) {
 
 	if strings.TrimSpace(value) == "" {
-		return b, &InvalidValueError{"Name", value}
+		return b, b.setInvalid(&InvalidValueError{"Name", value})
 	}
 
+	b.setValid("Name")
 	b.transaction.Customer.Name = value
 	return b, nil
 }
@@ -276,144 +608,596 @@ func (b *TransactionBuilder) Country(value string) (*TransactionBuilder, error)
 	regex, _ := regexp.Compile("^[a-zA-z]{2}$")
 
 	if !regex.MatchString(value) {
-		return b, &InvalidValueError{"Country", value}
+		return b, b.setInvalid(&InvalidValueError{"Country", value})
 	}
 
+	b.setValid("Country")
 	b.transaction.Customer.Country = value
 	return b, nil
 }
 
+// Document accepts a CPF or CNPJ, formatted or not, validated by its check
+// digits. The customer type follows the document type.
 func (b *TransactionBuilder) Document(value string) (*TransactionBuilder, error) {
 
-	regexCPF, _ := regexp.Compile("[0-9]{3}\\.[0-9]{3}\\.[0-9]{3}-[0-9]{2}")
-	regexCNPJ, _ := regexp.Compile("[0-9]{2}\\.[0-9]{3}\\.[0-9]{3}/[0-9]{4}-[0-9]{2}")
-
-	if regexCPF.MatchString(value) {
-		value = strings.Replace(value, ".", "", -1)
-		value = strings.Replace(value, "-", "", -1)
-		doc := document{DocumentType: CPF.String(), Number: value}
-		b.transaction.Customer.Documents = append(b.transaction.Customer.Documents, doc)
-		b.transaction.Customer.CustomerType = INDIVIDUAL.String()
-
-		return b, nil
+	documentType, number, err := documents.Validate(value)
+	if err != nil {
+		return b, b.setInvalid(&InvalidValueError{"Document.Number", value})
 	}
 
-	if regexCNPJ.MatchString(value) {
-
-		value = strings.Replace(value, ".", "", -1)
-		value = strings.Replace(value, "-", "", -1)
-		value = strings.Replace(value, "/", "", -1)
+	b.setValid("Document.Number")
+	doc := document{DocumentType: documentType.String(), Number: number}
+	b.transaction.Customer.Documents = append(b.transaction.Customer.Documents, doc)
 
-		doc := document{DocumentType: CNPJ.String(), Number: value}
-		b.transaction.Customer.Documents = append(b.transaction.Customer.Documents, doc)
+	if documentType == CNPJ {
 		b.transaction.Customer.CustomerType = CORPORATION.String()
-
-		return b, nil
+	} else {
+		b.transaction.Customer.CustomerType = INDIVIDUAL.String()
 	}
-	return b, &InvalidValueError{"Document.Number", value}
+
+	return b, nil
 }
 
 type client struct {
 	*http.Client
-	url string
+	url             string
+	apiKey          string
+	encryptionKey   string
+	userAgent       string
+	timeout         time.Duration
+	retryPolicy     RetryPolicy
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	publicKeyTTL    time.Duration
+	keys            *keyCache
+	logger          Logger
 }
 
-func NewClient() *client {
-	return &client{
-		new(http.Client),
-		BASE_URL,
+func NewClient(options ...Option) *client {
+	c := &client{
+		Client:          new(http.Client),
+		url:             BASE_URL,
+		userAgent:       USER_AGENT,
+		retryPolicy:     DefaultRetryPolicy,
+		pollInterval:    DEFAULT_POLL_INTERVAL,
+		maxPollInterval: DEFAULT_MAX_POLL_INTERVAL,
+		publicKeyTTL:    DEFAULT_PUBLIC_KEY_TTL,
+		logger:          nopLogger{},
 	}
-}
 
-func (c *client) Execute(transaction transaction, authenticationMethod AuthenticationMethod) (*transactionResponse, error) {
+	for _, option := range options {
+		option(c)
+	}
 
-	jsonData, _ := transaction.marshal()
-	reqUrl := c.url + PATH_TRANSACTION
-	req, _ := http.NewRequest(http.MethodPost, reqUrl, bytes.NewBuffer(jsonData))
-	req.Header.Set("Content-Type", "application/json")
+	if c.timeout > 0 {
+		httpClient := *c.Client
+		httpClient.Timeout = c.timeout
+		c.Client = &httpClient
+	}
+
+	c.keys = &keyCache{ttl: c.publicKeyTTL, fetch: c.fetchPublicKey, now: time.Now, logger: c.logger}
+
+	return c
+}
+
+// authenticate adds the api_key to req as required by authenticationMethod.
+// BODY is handled by the callers, which send the key inside the payload.
+func (c *client) authenticate(req *http.Request, authenticationMethod AuthenticationMethod) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	switch authenticationMethod {
 	case BODY:
 	case PARAM:
 		q := req.URL.Query()
-		q.Add("api_key", API_KEY)
+		q.Add("api_key", c.apiKey)
 		req.URL.RawQuery = q.Encode()
 	case BASIC_AUTH:
-		req.SetBasicAuth(API_KEY, "x")
+		req.SetBasicAuth(c.apiKey, "x")
+	}
+}
+
+// send performs the request built by newRequest, retrying it according to the
+// client retry policy. reconcile, when not nil, runs before every retry and
+// stops the loop, returning a nil response, when it reports true or fails,
+// in which case its error is returned: without knowing whether the previous
+// attempt went through, repeating it could create the operation twice.
+// Waiting between attempts is interrupted when ctx is done.
+func (c *client) send(ctx context.Context, newRequest func() *http.Request, reconcile func() (bool, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && reconcile != nil {
+			if done, err := reconcile(); done || err != nil {
+				return nil, err
+			}
+		}
+
+		req := newRequest()
+		c.logRequest(req)
+
+		policy := c.retryPolicy
+		if req.Method != http.MethodGet && reconcile == nil {
+			// Without a way to find out whether a failed attempt went
+			// through, only requests safe to repeat are retried.
+			policy = NoRetry
+		}
+
+		res, err := c.Do(req)
+		if urlErr, ok := err.(*url.Error); ok {
+			// The URL may carry the api_key or a document in its query.
+			urlErr.URL = redactURL(req.URL)
+		}
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		wait, retry := policy.next(attempt, res, err)
+		if !retry {
+			return res, err
+		}
+
+		if err != nil {
+			c.log().Warn("Retrying after error", "path", req.URL.Path, "attempt", attempt, "error", err.Error())
+		} else {
+			c.log().Warn("Retrying after status", "path", req.URL.Path, "attempt", attempt, "status", res.StatusCode)
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *client) Execute(transaction Transaction, authenticationMethod AuthenticationMethod) (*TransactionResponse, error) {
+	return c.ExecuteContext(context.Background(), transaction, authenticationMethod)
+}
+
+// ExecuteContext creates the transaction. The request, its retries and the
+// waits between them are abandoned as soon as ctx is cancelled or expires.
+func (c *client) ExecuteContext(ctx context.Context, transaction Transaction, authenticationMethod AuthenticationMethod) (*TransactionResponse, error) {
+
+	if authenticationMethod == BODY {
+		transaction.ApiKey = c.apiKey
 	}
 
-	reqDump, _ := httputil.DumpRequest(req, true)
-	log.Println("##### Request transaction #####")
-	log.Println(string(reqDump))
+	jsonData, _ := transaction.marshal()
+	reqUrl := c.url + PATH_TRANSACTION
 
-	res, err := c.Do(req)
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, reqUrl, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		c.authenticate(req, authenticationMethod)
+		return req
+	}
+
+	var existing *TransactionResponse
+	reconcile := func() (bool, error) {
+		var err error
+		existing, err = c.findByIdempotencyKey(ctx, transaction.IdempotencyKey())
+		return existing != nil, err
+	}
+
+	res, err := c.send(ctx, newRequest, reconcile)
+
+	if existing != nil {
+		c.log().Info("Transaction already created", "id", existing.ID)
+		return existing, nil
+	}
 
 	if err != nil {
-		log.Println("Stone response  error", err.Error())
+		c.log().Error("Pagar.me request failed", "path", PATH_TRANSACTION, "error", err.Error())
 		return nil, err
 	}
 
-	if res.StatusCode == 500 {
-		err := InternalError{PATH_TRANSACTION}
-		log.Println("Stone response  error", err.Error())
-		return nil, &err
+	result := TransactionResponse{}
+	if err := c.readResponse(res, PATH_TRANSACTION, &result); err != nil {
+		return nil, err
 	}
 
+	return &result, nil
+}
+
+// request sends payload, when not nil, as JSON to path authenticated with
+// BASIC_AUTH and decodes a successful response into out.
+func (c *client) request(ctx context.Context, method string, path string, payload interface{}, out interface{}) error {
+	var jsonData []byte
+	if payload != nil {
+		jsonData, _ = json.Marshal(payload)
+	}
+
+	newRequest := func() *http.Request {
+		var body io.Reader
+		if jsonData != nil {
+			body = bytes.NewBuffer(jsonData)
+		}
+
+		req, _ := http.NewRequestWithContext(ctx, method, c.url+path, body)
+		if jsonData != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		c.authenticate(req, BASIC_AUTH)
+		return req
+	}
+
+	res, err := c.send(ctx, newRequest, nil)
+	if err != nil {
+		c.log().Error("Pagar.me request failed", "path", redactPath(path), "error", err.Error())
+		return err
+	}
+
+	return c.readResponse(res, path, out)
+}
+
+// readResponse closes res after turning an error status into InternalError or
+// APIError, or decoding a successful body into out. The query of path is
+// redacted in the logs and errors.
+func (c *client) readResponse(res *http.Response, path string, out interface{}) error {
 	defer res.Body.Close()
+	path = redactPath(path)
 
-	repDump, _ := httputil.DumpResponse(res, true)
-	log.Println("##### Response  transaction ##### ")
-	log.Println(string(repDump))
+	if res.StatusCode == 500 {
+		err := InternalError{path}
+		c.log().Error("Pagar.me request failed", "path", path, "error", err.Error())
+		return &err
+	}
 
-	result := transactionResponse{}
-	json.NewDecoder(res.Body).Decode(result)
+	c.logResponse(res, path)
 
-	return &result, nil
+	if res.StatusCode >= http.StatusBadRequest {
+		err := newAPIError(res, path)
+		c.log().Error("Pagar.me request failed", "path", path, "error", err.Error())
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// logRequest logs req, with its body, redacted. The body is read from a copy
+// given by req.GetBody, so req can still be sent.
+func (c *client) logRequest(req *http.Request) {
+	if !logging(c.log()) {
+		return
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		if reader, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(reader)
+		}
+	}
+
+	c.log().Debug("Pagar.me request",
+		"method", req.Method,
+		"path", req.URL.Path,
+		"query", RedactQuery(req.URL.Query()).Encode(),
+		"header", RedactHeader(req.Header),
+		"body", RedactBody(body),
+	)
+}
+
+// logResponse logs res, with its body, redacted. The body is replaced by a
+// copy so it can still be decoded.
+func (c *client) logResponse(res *http.Response, path string) {
+	if !logging(c.log()) {
+		return
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.log().Debug("Pagar.me response",
+		"path", path,
+		"status", res.StatusCode,
+		"header", RedactHeader(res.Header),
+		"body", RedactBody(body),
+	)
+}
+
+// findByIdempotencyKey looks up a transaction created with the given key. It
+// returns nil and no error only when the lookup succeeded and found nothing.
+func (c *client) findByIdempotencyKey(ctx context.Context, key string) (*TransactionResponse, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	filter := TransactionFilter{Metadata: map[string]string{METADATA_IDEMPOTENCY_KEY: key}, Count: 1}
+	it := c.ListTransactionsContext(ctx, filter)
+
+	if it.Next() {
+		found := it.Transaction()
+		return &found, nil
+	}
+
+	if it.Err() != nil {
+		c.log().Warn("Error looking up transaction", "idempotency_key", key, "error", it.Err().Error())
+		return nil, it.Err()
+	}
+
+	return nil, nil
 }
 
-func (c *client) RecoverPublicKey() publicKey {
-	log.Println("Recover Public Key")
+func (c *client) RecoverPublicKey() (PublicKey, error) {
+	return c.RecoverPublicKeyContext(context.Background())
+}
 
-	var body = []byte(`{"api_key":"` + API_KEY + `"}`)
+// RecoverPublicKeyContext returns the public key used to create card hashes.
+// The key is cached by the client and only requested again when it expires.
+func (c *client) RecoverPublicKeyContext(ctx context.Context) (PublicKey, error) {
+	return c.keys.get(ctx)
+}
 
+// fetchPublicKey requests the key with the encryption_key, which Pagar.me
+// only accepts in the query, or else with the api_key as BASIC_AUTH.
+func (c *client) fetchPublicKey(ctx context.Context) (PublicKey, error) {
 	reqUrl := c.url + PATH_HASH
-	req, _ := http.NewRequest(http.MethodGet, reqUrl, bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	reqDump, _ := httputil.DumpRequest(req, true)
+	if c.encryptionKey != "" {
+		reqUrl += "?" + url.Values{"encryption_key": {c.encryptionKey}}.Encode()
+	}
 
-	log.Println("##### Request PUBLIC KEY ######")
-	log.Println(string(reqDump))
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+		authenticationMethod := BASIC_AUTH
+		if c.encryptionKey != "" {
+			// Only the User-Agent: the api_key is not sent with the
+			// encryption_key.
+			authenticationMethod = BODY
+		}
+		c.authenticate(req, authenticationMethod)
+		return req
+	}
 
-	res, errorRes := c.Do(req)
+	res, err := c.send(ctx, newRequest, nil)
+	if err != nil {
+		return PublicKey{}, err
+	}
 
-	if errorRes != nil {
-		log.Fatal("Error request PUBLIC KEY", errorRes.Error())
+	key := PublicKey{}
+	if err := c.readResponse(res, PATH_HASH, &key); err != nil {
+		return PublicKey{}, err
 	}
 
-	resDump, _ := httputil.DumpResponse(res, true)
-	log.Println("##### Response Stone PUBLIC KEY #####")
-	log.Println(string(resDump))
+	return key, nil
+}
+
+func (c *client) ExecuteWithCardHash(transaction Transaction, authenticationMethod AuthenticationMethod) (*TransactionResponse, error) {
+	return c.ExecuteWithCardHashContext(context.Background(), transaction, authenticationMethod)
+}
 
-	if res.StatusCode != 200 {
-		log.Fatal("Error request PUBLIC KEY. Status: ", res.StatusCode)
+// ExecuteWithCardHashContext replaces the card data of transaction with a
+// card hash made with the cached public key and creates it. When Pagar.me
+// rejects the hash, as it does after rotating the key, the key is requested
+// again and the transaction is sent once more. A transaction charging a
+// stored card by CardID has no card data to hash and is created as it is.
+func (c *client) ExecuteWithCardHashContext(ctx context.Context, transaction Transaction, authenticationMethod AuthenticationMethod) (*TransactionResponse, error) {
+	if transaction.CardID != "" && transaction.CardNumber == "" {
+		return c.ExecuteContext(ctx, transaction, authenticationMethod)
 	}
 
-	publicKey := publicKey{}
-	json.NewDecoder(res.Body).Decode(&publicKey)
+	for attempt := 1; ; attempt++ {
+		key, err := c.keys.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		hashed := transaction
+		if err := hashed.CreateCardHash(key); err != nil {
+			return nil, err
+		}
+
+		result, err := c.ExecuteContext(ctx, hashed, authenticationMethod)
+		if attempt == 1 && cardHashRejected(err) {
+			c.log().Warn("Card hash rejected, recovering a new public key", "key_id", key.Id)
+			c.keys.invalidate(key)
+			continue
+		}
+
+		return result, err
+	}
+}
 
-	return publicKey
+func cardHashRejected(err error) bool {
+	apiError, ok := err.(*APIError)
+	if !ok || !apiError.IsValidation() {
+		return false
+	}
+
+	_, found := apiError.Field("card_hash")
+	return found
 }
 
-func createRsaPublicKey(value string) *rsa.PublicKey {
+// newIdempotencyKey returns a random UUID (version 4).
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func createRsaPublicKey(value string) (*rsa.PublicKey, error) {
 	block, _ := pem.Decode([]byte(value))
 	if block == nil {
-		log.Fatal("failed to parse PEM block containing the public key")
+		return nil, &PEMParseError{}
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		log.Fatal("failed to parse DER encoded public key: " + err.Error())
+		return nil, &PEMParseError{err}
+	}
+
+	rsaPublicKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, &NonRSAKeyError{fmt.Sprintf("%T", pub)}
+	}
+
+	return rsaPublicKey, nil
+}
+
+// Email is the customer email, without a display name.
+func (b *TransactionBuilder) Email(value string) (*TransactionBuilder, error) {
+
+	if !validEmail(value) {
+		return b, b.setInvalid(&InvalidValueError{"Email", value})
+	}
+
+	b.setValid("Email")
+	b.transaction.Customer.Email = value
+	return b, nil
+}
+
+// PhoneNumber adds a customer phone number in E.164 format, such as
+// +55 11 99999-8888. See NormalizePhoneNumber. An invalid number is not
+// added and its error, named after the call as PhoneNumber[1], is kept until
+// ClearPhoneNumbers.
+func (b *TransactionBuilder) PhoneNumber(value string) (*TransactionBuilder, error) {
+	param := fmt.Sprintf("PhoneNumber[%v]", b.phoneNumbers)
+	b.phoneNumbers++
+
+	number, ok := NormalizePhoneNumber(value)
+	if !ok {
+		return b, b.setInvalid(&InvalidValueError{param, value})
+	}
+
+	b.transaction.Customer.PhoneNumbers = append(b.transaction.Customer.PhoneNumbers, number)
+	return b, nil
+}
+
+// ClearPhoneNumbers removes the phone numbers added so far and the errors of
+// the invalid ones.
+func (b *TransactionBuilder) ClearPhoneNumbers() *TransactionBuilder {
+	b.setValid("PhoneNumber")
+	b.phoneNumbers = 0
+	b.transaction.Customer.PhoneNumbers = nil
+	return b
+}
+
+// Birthday is the customer birth date, which must be in the past.
+func (b *TransactionBuilder) Birthday(value Date) (*TransactionBuilder, error) {
+
+	if value.Year() < 1900 || !value.Before(b.clock()) {
+		return b, b.setInvalid(&InvalidValueError{"Birthday", value.String()})
+	}
+
+	b.setValid("Birthday")
+	b.transaction.Customer.Birthday = &value
+	return b, nil
+}
+
+// Billing is the name and address of the card owner. Brazilian addresses
+// must have a valid CEP, sent as 8 digits, and state code.
+func (b *TransactionBuilder) Billing(value Billing) (*TransactionBuilder, error) {
+	b.setValid("Billing")
+
+	if strings.TrimSpace(value.Name) == "" {
+		return b, b.setInvalid(&InvalidValueError{"Billing.Name", value.Name})
+	}
+
+	address, err := validAddress("Billing.Address", value.Address)
+	if err != nil {
+		return b, b.setInvalid(err)
+	}
+
+	value.Address = address
+	b.transaction.Billing = &value
+	return b, nil
+}
+
+// Shipping is the delivery of the order, validated as Billing. The fee must
+// not be negative and the delivery date, when given, must not be in the
+// past.
+func (b *TransactionBuilder) Shipping(value Shipping) (*TransactionBuilder, error) {
+	b.setValid("Shipping")
+
+	if strings.TrimSpace(value.Name) == "" {
+		return b, b.setInvalid(&InvalidValueError{"Shipping.Name", value.Name})
+	}
+
+	if value.Fee < 0 {
+		return b, b.setInvalid(&InvalidValueError{"Shipping.Fee", value.Fee.Decimal()})
+	}
+
+	today := b.clock().Format(DATE_LAYOUT)
+	if !value.DeliveryDate.IsZero() && value.DeliveryDate.String() < today {
+		return b, b.setInvalid(&InvalidValueError{"Shipping.DeliveryDate", value.DeliveryDate.String()})
+	}
+
+	address, err := validAddress("Shipping.Address", value.Address)
+	if err != nil {
+		return b, b.setInvalid(err)
+	}
+
+	value.Address = address
+	b.transaction.Shipping = &value
+	return b, nil
+}
+
+// AddItem adds a line item of the order, used by the antifraud analysis. The
+// item needs an ID, a Title, a UnitPrice in centavos that is not negative
+// and a Quantity of at least 1. An invalid item is not added and its error,
+// named after the call as Item[1].Title, is kept until ClearItems.
+func (b *TransactionBuilder) AddItem(value Item) (*TransactionBuilder, error) {
+	param := fmt.Sprintf("Item[%v]", b.items)
+	b.items++
+
+	switch {
+	case strings.TrimSpace(value.ID) == "":
+		return b, b.setInvalid(&InvalidValueError{param + ".ID", value.ID})
+	case strings.TrimSpace(value.Title) == "":
+		return b, b.setInvalid(&InvalidValueError{param + ".Title", value.Title})
+	case value.UnitPrice < 0:
+		return b, b.setInvalid(&InvalidValueError{param + ".UnitPrice", value.UnitPrice.Decimal()})
+	case value.Quantity < 1:
+		return b, b.setInvalid(&InvalidValueError{param + ".Quantity", strconv.Itoa(value.Quantity)})
 	}
 
-	return pub.(*rsa.PublicKey)
+	b.transaction.Items = append(b.transaction.Items, value)
+	return b, nil
+}
+
+// ClearItems removes the items added so far and the errors of the invalid
+// ones.
+func (b *TransactionBuilder) ClearItems() *TransactionBuilder {
+	b.setValid("Item")
+	b.items = 0
+	b.transaction.Items = nil
+	return b
+}
+
+// itemsTotal returns the sum of the unit prices times the quantities.
+func itemsTotal(items []Item) Money {
+	var total Money
+	for _, item := range items {
+		total += item.UnitPrice * Money(item.Quantity)
+	}
+	return total
+}
+
+// SplitRules divides the transaction between recipients, replacing the
+// previous rules. The rules either all have a Percentage, which must sum to
+// 100, or all have an Amount, which must sum to the transaction amount. See
+// SplitAmounts for how the remainder centavos are divided.
+func (b *TransactionBuilder) SplitRules(rules ...SplitRule) (*TransactionBuilder, error) {
+	b.setValid("SplitRules")
+
+	if err := validSplitRules(rules); err != nil {
+		return b, b.setInvalid(err)
+	}
+
+	b.transaction.SplitRules = rules
+	return b, nil
 }
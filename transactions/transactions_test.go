@@ -1,23 +1,35 @@
 package transactions
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecuteError500(t *testing.T) {
 
 	tb := TransactionBuilder{}
-	tb.Amount(2.0)
+	tb.Amount(NewMoney(2, 0))
 	tb.Name("Leandro Greijal")
 	tb.Country("BR")
 	tb.PaymentMethod(BOLETO)
 	tb.Document("251.854.650-26")
 
-	transaction := tb.Build()
+	transaction, _ := tb.Build()
 
 	server := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -27,7 +39,7 @@ func TestExecuteError500(t *testing.T) {
 
 	defer server.Close()
 
-	client := client{server.Client(), server.URL}
+	client := client{Client: server.Client(), url: server.URL}
 	result, err := client.Execute(transaction, BASIC_AUTH)
 
 	assertTest := assert.New(t)
@@ -36,15 +48,103 @@ func TestExecuteError500(t *testing.T) {
 
 }
 
+func TestExecuteError400(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+
+	transaction, _ := tb.Build()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(400)
+			io.WriteString(w, `{"errors":[{"type":"invalid_parameter","parameter_name":"customer","message":"customer está faltando"}],"url":"/transactions","method":"post"}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL}
+	result, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.EqualError(err, "Pagar.me error. Status: 400 Path: /transactions. Errors: customer: customer está faltando")
+
+	apiError, ok := err.(*APIError)
+	assertTest.True(ok)
+	assertTest.True(apiError.IsValidation())
+	assertTest.Equal([]FieldError{{Type: "invalid_parameter", ParameterName: "customer", Message: "customer está faltando"}}, apiError.Errors)
+}
+
+func TestExecuteError401(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+
+	transaction, _ := tb.Build()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(401)
+			io.WriteString(w, `{"errors":[{"type":"action_forbidden","parameter_name":null,"message":"api_key inválida"}],"url":"/transactions","method":"post"}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL}
+	_, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	apiError, ok := err.(*APIError)
+	assertTest.True(ok)
+	assertTest.Equal(401, apiError.StatusCode)
+	assertTest.Equal("/transactions", apiError.Path)
+	assertTest.True(apiError.IsAuthentication())
+	assertTest.Equal("action_forbidden", apiError.Errors[0].Type)
+}
+
+func TestExecuteError429(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+
+	transaction, _ := tb.Build()
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(429)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL}
+	_, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Pagar.me error. Status: 429 Path: /transactions")
+	assertTest.True(err.(*APIError).IsRateLimited())
+}
+
 func TestExecuteBoletoBasicAuth(t *testing.T) {
 
 	tb := TransactionBuilder{}
-	tb.Amount(2.0)
+	tb.Amount(NewMoney(2, 0))
 	tb.Name("Leandro Greijal")
 	tb.Country("BR")
 	tb.PaymentMethod(BOLETO)
 	tb.Document("251.854.650-26")
-	transaction := tb.Build()
+	transaction, _ := tb.Build()
 
 	server := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +158,7 @@ func TestExecuteBoletoBasicAuth(t *testing.T) {
 
 	defer server.Close()
 
-	client := client{server.Client(), server.URL}
+	client := client{Client: server.Client(), url: server.URL}
 	result, err := client.Execute(transaction, BASIC_AUTH)
 
 	assertTest := assert.New(t)
@@ -66,53 +166,461 @@ func TestExecuteBoletoBasicAuth(t *testing.T) {
 	assertTest.NotNil(result)
 }
 
+func TestExecuteRetryServiceUnavailable(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	posts := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				io.WriteString(w, "[]")
+				return
+			}
+			posts++
+			if posts < 3 {
+				w.WriteHeader(503)
+				return
+			}
+			w.Header().Add("Content-Type", "application/json")
+			io.WriteString(w, "{\"id\": 1234, \"status\": \"waiting_payment\"}")
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+	result, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(3, posts)
+	assertTest.Equal(1234, result.ID)
+}
+
+func TestExecuteRetryReconcilesByIdempotencyKey(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	posts := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				assert.Equal(t, transaction.IdempotencyKey(), r.URL.Query().Get("metadata[idempotency_key]"))
+				io.WriteString(w, "[{\"id\": 4321, \"status\": \"waiting_payment\"}]")
+				return
+			}
+			posts++
+			w.WriteHeader(504)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+	result, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(1, posts)
+	assertTest.Equal(4321, result.ID)
+}
+
+func TestExecuteRetryStopsWhenLookupFails(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	posts := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.WriteHeader(500)
+				return
+			}
+			posts++
+			w.WriteHeader(504)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}}
+	result, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.Equal(1, posts)
+	assertTest.True(strings.HasPrefix(err.Error(), "Mundipagg internal error. Path: /transactions?"))
+}
+
+func TestExecuteRetryGivesUp(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	posts := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				io.WriteString(w, "[]")
+				return
+			}
+			posts++
+			w.WriteHeader(502)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}}
+	_, err := client.Execute(transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Equal(2, posts)
+	assertTest.EqualError(err, "Pagar.me error. Status: 502 Path: /transactions")
+}
+
+func TestExecuteContextDeadline(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}),
+	)
+
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: DefaultRetryPolicy}
+	result, err := client.ExecuteContext(ctx, transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.Nil(result)
+	assertTest.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestExecuteContextCancelledWhileWaitingRetry(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	transaction, _ := tb.Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	posts := 0
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				io.WriteString(w, "[]")
+				return
+			}
+			posts++
+			cancel()
+			w.WriteHeader(503)
+		}),
+	)
+
+	defer server.Close()
+
+	client := client{Client: server.Client(), url: server.URL, retryPolicy: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute}}
+	_, err := client.ExecuteContext(ctx, transaction, BASIC_AUTH)
+
+	assertTest := assert.New(t)
+	assertTest.ErrorIs(err, context.Canceled)
+	assertTest.Equal(1, posts)
+}
+
 func TestTransactionMarshal(t *testing.T) {
 	tb := TransactionBuilder{}
-	tb.Amount(2.0)
+	tb.Amount(NewMoney(2, 0))
 	tb.Name("Leandro Greijal")
 	tb.Country("BR")
 	tb.PaymentMethod(BOLETO)
 	tb.Document("251.854.650-26")
 
-	transaction := tb.Build()
+	transaction, _ := tb.Build()
+	transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = "key"
 	json, _ := transaction.marshal()
 
 	assertTest := assert.New(t)
-	expectJson := "{\"api_key\":\"ak_test_qCS4GVwDKJhzbTn0Z3KIU4p4k79U17\",\"amount\":200,\"payment_method\":\"boleto\",\"customer\":{\"name\":\"Leandro Greijal\",\"country\":\"BR\",\"type\":\"individual\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]}}"
+	expectJson := "{\"amount\":200,\"payment_method\":\"boleto\",\"metadata\":{\"idempotency_key\":\"key\"},\"customer\":{\"name\":\"Leandro Greijal\",\"country\":\"BR\",\"type\":\"individual\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]}}"
 
 	assertTest.Equal(expectJson, string(json))
 
 }
 
+func pemPublicKey(t *testing.T, pub interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func cardTransaction() Transaction {
+	return Transaction{
+		CardNumber:         "4111111111111111",
+		CardHolderName:     "Leandro",
+		CardExpirationDate: "1030",
+		CardCVV:            "123",
+	}
+}
+
+func TestCreateCardHash(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	transaction := cardTransaction()
+
+	err := transaction.CreateCardHash(PublicKey{Id: 7, PublicKey: pemPublicKey(t, &privateKey.PublicKey)})
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Empty(transaction.CardNumber)
+	assertTest.Empty(transaction.CardCVV)
+
+	parts := strings.SplitN(transaction.CardHash, "_", 2)
+	assertTest.Equal("7", parts[0])
+	encrypted, _ := base64.StdEncoding.DecodeString(parts[1])
+	decrypted, err := rsa.DecryptPKCS1v15(rand.Reader, privateKey, encrypted)
+	assertTest.Nil(err)
+	card, _ := url.ParseQuery(string(decrypted))
+	assertTest.Equal("4111111111111111", card.Get("card_number"))
+	assertTest.Equal("1030", card.Get("card_expiration_date"))
+}
+
+func TestCreateCardHashInvalidPEM(t *testing.T) {
+	transaction := cardTransaction()
+	err := transaction.CreateCardHash(PublicKey{Id: 7, PublicKey: "key"})
+
+	assertTest := assert.New(t)
+	_, ok := err.(*PEMParseError)
+	assertTest.True(ok)
+	assertTest.Equal(cardTransaction(), transaction)
+}
+
+func TestCreateCardHashInvalidDER(t *testing.T) {
+	transaction := cardTransaction()
+	key := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")}))
+	err := transaction.CreateCardHash(PublicKey{Id: 7, PublicKey: key})
+
+	parseError, ok := err.(*PEMParseError)
+	assertTest := assert.New(t)
+	assertTest.True(ok)
+	assertTest.NotNil(parseError.Err)
+}
+
+func TestCreateCardHashNonRSA(t *testing.T) {
+	privateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	transaction := cardTransaction()
+	err := transaction.CreateCardHash(PublicKey{Id: 7, PublicKey: pemPublicKey(t, &privateKey.PublicKey)})
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "public key is *ecdsa.PublicKey, not RSA")
+	assertTest.Equal(cardTransaction(), transaction)
+}
+
+func TestCreateCardHashEncryptionError(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	transaction := cardTransaction()
+	transaction.CardHolderName = strings.Repeat("Leandro ", 20)
+	err := transaction.CreateCardHash(PublicKey{Id: 7, PublicKey: pemPublicKey(t, &privateKey.PublicKey)})
+
+	assertTest := assert.New(t)
+	_, ok := err.(*EncryptionError)
+	assertTest.True(ok)
+	assertTest.True(errors.Is(err, rsa.ErrMessageTooLong))
+	assertTest.Empty(transaction.CardHash)
+}
+
+func TestRecoverPublicKeyError(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"errors": [{"type": "action_forbidden", "message": "encryption_key inválida"}]}`)
+		}),
+	)
+
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("ak_test_key"), WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	_, err := client.RecoverPublicKey()
+
+	apiError, ok := err.(*APIError)
+	assertTest := assert.New(t)
+	assertTest.True(ok)
+	assertTest.True(apiError.IsAuthentication())
+}
+
+func TestRecoverPublicKeyUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	_, err := client.RecoverPublicKey()
+
+	assertTest := assert.New(t)
+	assertTest.NotNil(err)
+}
+
 func TestTransactionBuild(t *testing.T) {
 
 	tb := TransactionBuilder{}
-	tb.Amount(2.0)
+	tb.Amount(NewMoney(2, 0))
 	tb.Name("Leandro Greijal")
 	tb.Country("BR")
 	tb.PaymentMethod(BOLETO)
 	tb.Document("251.854.650-26")
 
-	transactionTest := tb.Build()
-	expect := transaction{}
-	expect.ApiKey = "ak_test_qCS4GVwDKJhzbTn0Z3KIU4p4k79U17"
+	transactionTest, err := tb.Build()
+	expect := Transaction{}
 	expect.Amount = 200
 	expect.Customer.Name = "Leandro Greijal"
 	expect.Customer.Country = "BR"
 	expect.Customer.CustomerType = INDIVIDUAL.String()
 	expect.PaymentMethod = BOLETO.String()
 	expect.Customer.Documents = append(expect.Customer.Documents, document{Number: "25185465026", DocumentType: CPF.String()})
+	expect.Metadata = map[string]string{METADATA_IDEMPOTENCY_KEY: transactionTest.IdempotencyKey()}
 
 	assertTest := assert.New(t)
+	assertTest.Nil(err)
 	assertTest.Equal(expect, transactionTest)
+	assertTest.Regexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", transactionTest.IdempotencyKey())
+
+}
+
+func TestTransactionBuildErrors(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardNumber("4111111111111112")
+	tb.Name("")
+	tb.Document("251.854.650-26")
+
+	transactionTest, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.Equal(Transaction{}, transactionTest)
+	assertTest.EqualError(err, "Invalid transaction: CardNumber is invalid. Value: 4111111111111112; Name is invalid. Value: ; CardHolderName is required; CardExpirationDate is required; CardCVV is required")
+	assertTest.Len(err.(*BuildError).Errors, 5)
+}
+
+func TestTransactionBuildFixedField(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-27")
+
+	_, err := tb.Build()
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: Document.Number is invalid. Value: 251.854.650-27")
+
+	tb.Document("251.854.650-26")
+	transactionTest, err := tb.Build()
+	assertTest.Nil(err)
+	assertTest.Equal(NewMoney(2, 0), transactionTest.Amount)
+}
+
+func TestTransactionBuildMissing(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: Amount is required; PaymentMethod is required")
+
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(BOLETO)
+	_, err = tb.Build()
+	assertTest.EqualError(err, "Invalid transaction: Document.Number is required")
+}
+
+func TestTransactionBuildCVVBrand(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardCVV("123")
+	tb.CardNumber("378282246310005")
+	tb.CardHolderName("Leandro")
+	tb.CardExpirationDate("12/40")
+
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: CardCVV is invalid. Value: 123")
+}
+
+func TestTransactionBuildCVVBeforeNumber(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	_, err := tb.CardCVV("1234")
+	tb.CardNumber("378282246310005")
+	tb.CardHolderName("Leandro")
+	tb.CardExpirationDate("12/40")
+
+	transaction, buildErr := tb.Build()
 
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Nil(buildErr)
+	assertTest.Equal("1234", transaction.CardCVV)
+}
+
+func TestTransactionBuildCVVLongerThanBrand(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardNumber("4111111111111111")
+	_, err := tb.CardCVV("1234")
+	tb.CardHolderName("Leandro")
+	tb.CardExpirationDate("12/40")
+
+	_, buildErr := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.EqualError(buildErr, "Invalid transaction: CardCVV is invalid. Value: 1234")
 }
 
 func TestCreateDocumentCPF(t *testing.T) {
 
 	tb := TransactionBuilder{}
 	tb.Document("251.854.650-26")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("cpf", transactionTest.Customer.Documents[0].DocumentType)
@@ -125,7 +633,7 @@ func TestCreateDocumentCNPJ(t *testing.T) {
 
 	tb := TransactionBuilder{}
 	tb.Document("30.516.297/0001-03")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("cnpj", transactionTest.Customer.Documents[0].DocumentType)
@@ -133,6 +641,29 @@ func TestCreateDocumentCNPJ(t *testing.T) {
 
 }
 
+func TestCreateDocumentUnformatted(t *testing.T) {
+
+	tb := TransactionBuilder{}
+	tb.Document("30516297000103")
+	transactionTest := tb.transaction
+
+	assertTest := assert.New(t)
+	assertTest.Equal("cnpj", transactionTest.Customer.Documents[0].DocumentType)
+	assertTest.Equal("30516297000103", transactionTest.Customer.Documents[0].Number)
+}
+
+func TestCreateDocumentCheckDigits(t *testing.T) {
+	tests := []string{"000.000.000-00", "251.854.650-27", "x123.456.789-00y", "30.516.297/0001-04"}
+
+	for _, value := range tests {
+		tb := TransactionBuilder{}
+		_, err := tb.Document(value)
+
+		assertTest := assert.New(t)
+		assertTest.EqualError(err, "Document.Number is invalid. Value: "+value)
+	}
+}
+
 func TestCreateDocumenInvalid(t *testing.T) {
 
 	tb := TransactionBuilder{}
@@ -153,7 +684,7 @@ func TestCountryInvalid(t *testing.T) {
 func TestCountry(t *testing.T) {
 	tb := TransactionBuilder{}
 	tb.Country("BR")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("BR", transactionTest.Customer.Country)
@@ -170,7 +701,7 @@ func TestNameInvalid(t *testing.T) {
 func TestName(t *testing.T) {
 	tb := TransactionBuilder{}
 	tb.Name("Name")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("Name", transactionTest.Customer.Name)
@@ -179,7 +710,7 @@ func TestName(t *testing.T) {
 func TestPaymentMethod(t *testing.T) {
 	tb := TransactionBuilder{}
 	tb.PaymentMethod(BOLETO)
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal(BOLETO.String(), transactionTest.PaymentMethod)
@@ -188,7 +719,7 @@ func TestPaymentMethod(t *testing.T) {
 func TestCardCVV(t *testing.T) {
 	tb := TransactionBuilder{}
 	tb.CardCVV("123")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("123", transactionTest.CardCVV)
@@ -196,10 +727,10 @@ func TestCardCVV(t *testing.T) {
 
 func TestCardCVVSize(t *testing.T) {
 	tb := TransactionBuilder{}
-	_, err := tb.CardCVV("1234")
+	_, err := tb.CardCVV("12345")
 
 	assertTest := assert.New(t)
-	assertTest.EqualError(err, "CardCVV is invalid. Value: 1234")
+	assertTest.EqualError(err, "CardCVV is invalid. Value: 12345")
 
 }
 
@@ -214,11 +745,47 @@ func TestCardCVVNoNumeric(t *testing.T) {
 
 func TestCardNumber(t *testing.T) {
 	tb := TransactionBuilder{}
-	tb.CardNumber("123")
-	transactionTest := tb.Build()
+	tb.CardNumber("4111111111111111")
+	transactionTest := tb.transaction
+
+	assertTest := assert.New(t)
+	assertTest.Equal("4111111111111111", transactionTest.CardNumber)
+}
+
+func TestCardNumberLuhn(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.CardNumber("4111111111111112")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "CardNumber is invalid. Value: 4111111111111112")
+}
+
+func TestCardNumberBrandLength(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.CardNumber("37828224631000")
 
 	assertTest := assert.New(t)
-	assertTest.Equal("123", transactionTest.CardNumber)
+	assertTest.EqualError(err, "CardNumber is invalid. Value: 37828224631000")
+}
+
+func TestCardCVVAmex(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardNumber("378282246310005")
+	tb.CardHolderName("Leandro")
+	tb.CardExpirationDate("12/40")
+	_, err := tb.CardCVV("123")
+	_, buildErr := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.EqualError(buildErr, "Invalid transaction: CardCVV is invalid. Value: 123")
+
+	tb.CardCVV("1234")
+	transaction, buildErr := tb.Build()
+	assertTest.Nil(buildErr)
+	assertTest.Equal("1234", transaction.CardCVV)
 }
 
 func TestCardCardNumberSize(t *testing.T) {
@@ -237,15 +804,51 @@ func TestCardCardNumberNoNumeric(t *testing.T) {
 	assertTest.EqualError(err, "CardNumber is invalid. Value: a12")
 }
 
+func clock(year int, month time.Month, day int) func() time.Time {
+	return func() time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+}
+
 func TestCardExpirationDate(t *testing.T) {
 	tb := TransactionBuilder{}
+	tb.Clock(clock(2021, time.January, 31))
 	tb.CardExpirationDate("0121")
-	transactionTest := tb.Build()
+	transactionTest := tb.transaction
 
 	assertTest := assert.New(t)
 	assertTest.Equal("0121", transactionTest.CardExpirationDate)
 }
 
+func TestCardExpirationDateFormats(t *testing.T) {
+	for _, value := range []string{"0328", "03/28", "03/2028"} {
+		tb := TransactionBuilder{}
+		tb.Clock(clock(2021, time.January, 1))
+		tb.CardExpirationDate(value)
+		transactionTest := tb.transaction
+
+		assertTest := assert.New(t)
+		assertTest.Equal("0328", transactionTest.CardExpirationDate)
+	}
+}
+
+func TestCardExpirationDateExpired(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Clock(clock(2021, time.February, 1))
+	_, err := tb.CardExpirationDate("0121")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "CardExpirationDate is invalid. Value: 0121")
+}
+
+func TestCardExpirationDateMonth(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.CardExpirationDate("1399")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "CardExpirationDate is invalid. Value: 1399")
+}
+
 func TestCardExpirationDateSize(t *testing.T) {
 	tb := TransactionBuilder{}
 	_, err := tb.CardExpirationDate("")
@@ -261,3 +864,395 @@ func TestCardCardExpirationDateNoNumeric(t *testing.T) {
 	assertTest := assert.New(t)
 	assertTest.EqualError(err, "CardExpirationDate is invalid. Value: a12")
 }
+
+func TestInstallments(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.Installments(3)
+	transactionTest := tb.transaction
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal(3, transactionTest.Installments)
+}
+
+func TestInstallmentsInvalid(t *testing.T) {
+	tb := TransactionBuilder{}
+
+	assertTest := assert.New(t)
+	_, err := tb.Installments(0)
+	assertTest.EqualError(err, "Installments is invalid. Value: 0")
+	_, err = tb.Installments(13)
+	assertTest.EqualError(err, "Installments is invalid. Value: 13")
+}
+
+func TestInstallmentsBoleto(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.Name("Leandro Greijal")
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	tb.Installments(2)
+
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: Installments is invalid. Value: 2")
+}
+
+func TestPostbackURL(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.PostbackURL("https://loja.example.com/postback")
+	transactionTest := tb.transaction
+
+	assertTest := assert.New(t)
+	assertTest.Equal("https://loja.example.com/postback", transactionTest.PostbackURL)
+}
+
+func TestPostbackURLInvalid(t *testing.T) {
+	tb := TransactionBuilder{}
+	_, err := tb.PostbackURL("/postback")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "PostbackURL is invalid. Value: /postback")
+}
+
+func boletoBuilder() *TransactionBuilder {
+	tb := &TransactionBuilder{}
+	tb.Clock(clock(2024, time.March, 1))
+	tb.Amount(NewMoney(2, 0))
+	tb.Name("Leandro Greijal")
+	tb.PaymentMethod(BOLETO)
+	tb.Document("251.854.650-26")
+	return tb
+}
+
+func TestBoletoOptionsMarshal(t *testing.T) {
+	tb := boletoBuilder()
+	tb.BoletoExpirationDate(date(2024, time.March, 8))
+	tb.BoletoInstructions("Não receber após o vencimento")
+	tb.BoletoFine(BoletoCharge{Days: 1, Percentage: 200})
+	tb.BoletoInterest(BoletoCharge{Days: 1, Amount: 10})
+	tb.BoletoRules(STRICT_EXPIRATION_DATE)
+	tb.Async(false)
+
+	transaction, err := tb.Build()
+	transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = "key"
+	json, _ := transaction.marshal()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	expectJson := "{\"amount\":200,\"payment_method\":\"boleto\",\"async\":false,\"boleto_expiration_date\":\"2024-03-08\"," +
+		"\"boleto_instructions\":\"Não receber após o vencimento\",\"boleto_fine\":{\"days\":1,\"percentage\":2.00}," +
+		"\"boleto_interest\":{\"days\":1,\"amount\":10},\"boleto_rules\":[\"strict_expiration_date\"]," +
+		"\"metadata\":{\"idempotency_key\":\"key\"},\"customer\":{\"name\":\"Leandro Greijal\",\"type\":\"individual\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]}}"
+	assertTest.Equal(expectJson, string(json))
+}
+
+func TestBoletoExpirationDatePast(t *testing.T) {
+	tb := boletoBuilder()
+
+	assertTest := assert.New(t)
+	_, err := tb.BoletoExpirationDate(date(2024, time.March, 1))
+	assertTest.EqualError(err, "BoletoExpirationDate is invalid. Value: 2024-03-01")
+	_, err = tb.BoletoExpirationDate(date(2024, time.February, 28))
+	assertTest.EqualError(err, "BoletoExpirationDate is invalid. Value: 2024-02-28")
+	_, err = tb.BoletoExpirationDate(date(2024, time.March, 2))
+	assertTest.Nil(err)
+}
+
+func TestBoletoInstructionsSize(t *testing.T) {
+	tb := boletoBuilder()
+
+	assertTest := assert.New(t)
+	_, err := tb.BoletoInstructions(strings.Repeat("á", MAX_BOLETO_INSTRUCTIONS))
+	assertTest.Nil(err)
+	_, err = tb.BoletoInstructions(strings.Repeat("a", MAX_BOLETO_INSTRUCTIONS+1))
+	assertTest.NotNil(err)
+	_, err = tb.BoletoInstructions(" ")
+	assertTest.NotNil(err)
+}
+
+func TestBoletoChargeInvalid(t *testing.T) {
+	tests := []BoletoCharge{
+		{Days: 0, Amount: 100},
+		{Days: 1},
+		{Days: 1, Amount: 100, Percentage: 200},
+		{Days: 1, Amount: -100},
+	}
+
+	for _, charge := range tests {
+		tb := boletoBuilder()
+		_, err := tb.BoletoFine(charge)
+		assert.New(t).EqualError(err, "BoletoFine is invalid. Value: "+charge.String())
+		_, err = tb.BoletoInterest(charge)
+		assert.New(t).EqualError(err, "BoletoInterest is invalid. Value: "+charge.String())
+	}
+}
+
+func TestBoletoRulesInvalid(t *testing.T) {
+	tb := boletoBuilder()
+	_, err := tb.BoletoRules("strict")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "BoletoRules is invalid. Value: strict")
+}
+
+func TestBoletoOptionsCreditCard(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Clock(clock(2024, time.March, 1))
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardNumber("4111111111111111")
+	tb.CardHolderName("Leandro")
+	tb.CardExpirationDate("1030")
+	tb.CardCVV("123")
+	tb.BoletoExpirationDate(date(2024, time.March, 8))
+	tb.BoletoFine(BoletoCharge{Days: 1, Percentage: 200})
+
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: BoletoExpirationDate is invalid. Value: 2024-03-08; BoletoFine is invalid. Value: 2.00% after 1 days")
+}
+
+func TestEmail(t *testing.T) {
+	tb := TransactionBuilder{}
+
+	assertTest := assert.New(t)
+	_, err := tb.Email("leandro@example.com")
+	assertTest.Nil(err)
+	assertTest.Equal("leandro@example.com", tb.transaction.Customer.Email)
+
+	for _, value := range []string{"leandro", "Leandro <leandro@example.com>", ""} {
+		_, err = tb.Email(value)
+		assertTest.EqualError(err, "Email is invalid. Value: "+value)
+	}
+}
+
+func TestPhoneNumber(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.PhoneNumber("+55 (11) 99999-8888")
+	_, err := tb.PhoneNumber("11999998888")
+	tb.PhoneNumber("+55 11 3333-4444")
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "PhoneNumber[1] is invalid. Value: 11999998888")
+	assertTest.Equal([]string{"+5511999998888", "+551133334444"}, tb.transaction.Customer.PhoneNumbers)
+	assertTest.True(tb.hasInvalid("PhoneNumber[1]"))
+
+	tb.ClearPhoneNumbers()
+	tb.PhoneNumber("+55 11 3333-4444")
+	assertTest.Equal([]string{"+551133334444"}, tb.transaction.Customer.PhoneNumbers)
+	assertTest.Empty(tb.invalid)
+}
+
+func TestTransactionBuildInvalidPhoneNumber(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Amount(NewMoney(2, 0))
+	tb.PaymentMethod(CREDIT_CARD)
+	tb.CardID("card_abc")
+	tb.PhoneNumber("bad")
+	tb.PhoneNumber("also bad")
+	tb.PhoneNumber("+5511999998888")
+
+	_, err := tb.Build()
+
+	assert.New(t).EqualError(err, "Invalid transaction: PhoneNumber[0] is invalid. Value: bad; PhoneNumber[1] is invalid. Value: also bad")
+}
+
+func TestBirthday(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Clock(clock(2024, time.March, 1))
+
+	assertTest := assert.New(t)
+	_, err := tb.Birthday(NewDate(1990, time.May, 20))
+	assertTest.Nil(err)
+	assertTest.Equal("1990-05-20", tb.transaction.Customer.Birthday.String())
+
+	_, err = tb.Birthday(NewDate(2024, time.March, 2))
+	assertTest.EqualError(err, "Birthday is invalid. Value: 2024-03-02")
+	_, err = tb.Birthday(NewDate(1800, time.March, 2))
+	assertTest.EqualError(err, "Birthday is invalid. Value: 1800-03-02")
+}
+
+func TestBilling(t *testing.T) {
+	tb := TransactionBuilder{}
+
+	assertTest := assert.New(t)
+	_, err := tb.Billing(Billing{Name: "Leandro", Address: address()})
+	assertTest.Nil(err)
+	assertTest.Equal("01310100", tb.transaction.Billing.Address.Zipcode)
+
+	_, err = tb.Billing(Billing{Address: address()})
+	assertTest.EqualError(err, "Billing.Name is invalid. Value: ")
+}
+
+func TestBillingFixed(t *testing.T) {
+	tb := TransactionBuilder{}
+	invalid := address()
+	invalid.Zipcode = "0131"
+
+	tb.Billing(Billing{Name: "Leandro", Address: invalid})
+	assertTest := assert.New(t)
+	assertTest.True(tb.hasInvalid("Billing.Address.Zipcode"))
+
+	tb.Billing(Billing{Name: "Leandro", Address: address()})
+	assertTest.Empty(tb.invalid)
+}
+
+func TestShipping(t *testing.T) {
+	tb := TransactionBuilder{}
+	tb.Clock(clock(2024, time.March, 1))
+
+	assertTest := assert.New(t)
+	_, err := tb.Shipping(Shipping{Name: "Leandro", Fee: 1000, DeliveryDate: NewDate(2024, time.March, 5), Expedited: true, Address: address()})
+	assertTest.Nil(err)
+	assertTest.Equal(Money(1000), tb.transaction.Shipping.Fee)
+
+	_, err = tb.Shipping(Shipping{Name: "Leandro", Fee: -1, Address: address()})
+	assertTest.EqualError(err, "Shipping.Fee is invalid. Value: -0.01")
+
+	_, err = tb.Shipping(Shipping{Name: "Leandro", DeliveryDate: NewDate(2024, time.February, 29), Address: address()})
+	assertTest.EqualError(err, "Shipping.DeliveryDate is invalid. Value: 2024-02-29")
+}
+
+func TestCustomerMarshal(t *testing.T) {
+	tb := boletoBuilder()
+	tb.Email("leandro@example.com")
+	tb.PhoneNumber("+5511999998888")
+	tb.Birthday(NewDate(1990, time.May, 20))
+	tb.Billing(Billing{Name: "Leandro", Address: address()})
+	tb.Shipping(Shipping{Name: "Leandro", Fee: 1000, DeliveryDate: NewDate(2024, time.March, 5), Address: address()})
+
+	transaction, err := tb.Build()
+	transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = "key"
+	json, _ := transaction.marshal()
+
+	addressJson := "{\"street\":\"Avenida Paulista\",\"street_number\":\"1000\",\"neighborhood\":\"Bela Vista\",\"city\":\"São Paulo\",\"state\":\"sp\",\"zipcode\":\"01310100\",\"country\":\"br\"}"
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("{\"amount\":200,\"payment_method\":\"boleto\",\"metadata\":{\"idempotency_key\":\"key\"},"+
+		"\"customer\":{\"name\":\"Leandro Greijal\",\"type\":\"individual\",\"email\":\"leandro@example.com\",\"phone_numbers\":[\"+5511999998888\"],\"birthday\":\"1990-05-20\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]},"+
+		"\"billing\":{\"name\":\"Leandro\",\"address\":"+addressJson+"},"+
+		"\"shipping\":{\"name\":\"Leandro\",\"fee\":1000,\"delivery_date\":\"2024-03-05\",\"expedited\":false,\"address\":"+addressJson+"}}", string(json))
+}
+
+func TestAddItem(t *testing.T) {
+	tb := TransactionBuilder{}
+
+	assertTest := assert.New(t)
+	_, err := tb.AddItem(Item{ID: "r123", Title: "Camiseta", UnitPrice: 2990, Quantity: 2, Tangible: true})
+	assertTest.Nil(err)
+
+	_, err = tb.AddItem(Item{ID: "r124", Title: "", UnitPrice: 100, Quantity: 1})
+	assertTest.EqualError(err, "Item[1].Title is invalid. Value: ")
+	_, err = tb.AddItem(Item{ID: "r125", Title: "Boné", UnitPrice: -100, Quantity: 1})
+	assertTest.EqualError(err, "Item[2].UnitPrice is invalid. Value: -1.00")
+	_, err = tb.AddItem(Item{ID: "r126", Title: "Boné", UnitPrice: 100, Quantity: 0})
+	assertTest.EqualError(err, "Item[3].Quantity is invalid. Value: 0")
+	_, err = tb.AddItem(Item{ID: "r127", Title: "Boné", UnitPrice: 100, Quantity: 0})
+	assertTest.EqualError(err, "Item[4].Quantity is invalid. Value: 0")
+
+	assertTest.Len(tb.transaction.Items, 1)
+	assertTest.Len(tb.invalid, 4)
+
+	tb.ClearItems()
+	_, err = tb.AddItem(Item{ID: "r128", Title: "Boné", UnitPrice: 100, Quantity: 1})
+	assertTest.Nil(err)
+	assertTest.Equal("r128", tb.transaction.Items[0].ID)
+	assertTest.Len(tb.transaction.Items, 1)
+	assertTest.Empty(tb.invalid)
+}
+
+func TestTransactionBuildInvalidItem(t *testing.T) {
+	tb := boletoBuilder()
+	tb.AddItem(Item{ID: "r123", Title: "", UnitPrice: 200, Quantity: 1})
+	tb.AddItem(Item{ID: "r124", Title: "Boné", UnitPrice: 200, Quantity: 1})
+
+	_, err := tb.Build()
+
+	assert.New(t).EqualError(err, "Invalid transaction: Item[0].Title is invalid. Value: ")
+}
+
+func TestItemsAmount(t *testing.T) {
+	tb := boletoBuilder()
+	tb.Amount(NewMoney(69, 80))
+	tb.AddItem(Item{ID: "r123", Title: "Camiseta", UnitPrice: 2990, Quantity: 2, Tangible: true})
+	tb.Shipping(Shipping{Name: "Leandro", Fee: 1000, Address: address()})
+
+	transaction, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Len(transaction.Items, 1)
+}
+
+func TestItemsAmountMismatch(t *testing.T) {
+	tb := boletoBuilder()
+	tb.AddItem(Item{ID: "r123", Title: "Camiseta", UnitPrice: 2990, Quantity: 2, Tangible: true})
+
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: Amount R$ 2,00 does not match the items R$ 59,80 plus the shipping fee R$ 0,00")
+	var mismatch *AmountMismatchError
+	assertTest.True(errors.As(err.(*BuildError).Errors[0], &mismatch))
+	assertTest.Equal(Money(5980), mismatch.Items)
+}
+
+func TestItemsMarshal(t *testing.T) {
+	tb := boletoBuilder()
+	tb.AddItem(Item{ID: "r123", Title: "Camiseta", UnitPrice: 100, Quantity: 2, Tangible: true})
+
+	transaction, _ := tb.Build()
+	transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = "key"
+	json, _ := transaction.marshal()
+
+	assert.New(t).Equal("{\"amount\":200,\"payment_method\":\"boleto\",\"metadata\":{\"idempotency_key\":\"key\"},"+
+		"\"customer\":{\"name\":\"Leandro Greijal\",\"type\":\"individual\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]},"+
+		"\"items\":[{\"id\":\"r123\",\"title\":\"Camiseta\",\"unit_price\":100,\"quantity\":2,\"tangible\":true}]}", string(json))
+}
+
+func TestSplitRules(t *testing.T) {
+	tb := boletoBuilder()
+	_, err := tb.SplitRules(
+		SplitRule{RecipientID: "re_1", Percentage: 85, Liable: true, ChargeProcessingFee: true, ChargeRemainderFee: true},
+		SplitRule{RecipientID: "re_2", Percentage: 15},
+	)
+
+	transaction, _ := tb.Build()
+	transaction.Metadata[METADATA_IDEMPOTENCY_KEY] = "key"
+	json, _ := transaction.marshal()
+
+	assertTest := assert.New(t)
+	assertTest.Nil(err)
+	assertTest.Equal("{\"amount\":200,\"payment_method\":\"boleto\",\"metadata\":{\"idempotency_key\":\"key\"},"+
+		"\"customer\":{\"name\":\"Leandro Greijal\",\"type\":\"individual\",\"documents\":[{\"type\":\"cpf\",\"number\":\"25185465026\"}]},"+
+		"\"split_rules\":[{\"recipient_id\":\"re_1\",\"percentage\":85,\"liable\":true,\"charge_processing_fee\":true,\"charge_remainder_fee\":true},"+
+		"{\"recipient_id\":\"re_2\",\"percentage\":15,\"liable\":false,\"charge_processing_fee\":false,\"charge_remainder_fee\":false}]}", string(json))
+}
+
+func TestSplitRulesAmountMismatch(t *testing.T) {
+	tb := boletoBuilder()
+	tb.SplitRules(SplitRule{RecipientID: "re_1", Amount: 150}, SplitRule{RecipientID: "re_2", Amount: 100})
+
+	_, err := tb.Build()
+
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "Invalid transaction: Split rule amounts sum to R$ 2,50, not the amount R$ 2,00")
+
+	tb.Amount(NewMoney(2, 50))
+	_, err = tb.Build()
+	assertTest.Nil(err)
+}
+
+func TestSplitRulesFixed(t *testing.T) {
+	tb := boletoBuilder()
+
+	_, err := tb.SplitRules(SplitRule{RecipientID: "re_1", Percentage: 120})
+	assertTest := assert.New(t)
+	assertTest.EqualError(err, "SplitRules.Percentage is invalid. Value: 120")
+
+	tb.SplitRules(SplitRule{RecipientID: "re_1", Percentage: 100})
+	assertTest.Empty(tb.invalid)
+}